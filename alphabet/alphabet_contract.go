@@ -22,7 +22,7 @@ const (
 
 	notaryDisabledKey = "notary"
 
-	version = 1
+	version = 2
 )
 
 // OnNEP17Payment is a callback for NEP-17 compatible native GAS and NEO contracts.
@@ -33,6 +33,24 @@ func OnNEP17Payment(from interop.Hash160, amount int, data interface{}) {
 	}
 }
 
+// Pausable methods of the contract, see Pause.
+var (
+	// userMethods are stopped by the pause of the whole contract.
+	userMethods = []string{}
+	// systemMethods are invoked by inner ring nodes, so they are stopped
+	// only by their own pause.
+	systemMethods = []string{"emit", "vote"}
+)
+
+func _deploy(data interface{}, isUpdate bool) {
+	ctx := storage.GetContext()
+
+	prev := common.CommitVersion(ctx, version)
+	if isUpdate {
+		migrateStorage(ctx, prev)
+	}
+}
+
 func Init(notaryDisabled bool, owner interop.Hash160, addrNetmap, addrProxy interop.Hash160, name string, index, total int) {
 	ctx := storage.GetContext()
 
@@ -40,6 +58,8 @@ func Init(notaryDisabled bool, owner interop.Hash160, addrNetmap, addrProxy inte
 		panic("only owner can reinitialize contract")
 	}
 
+	common.CheckNotGoverned(ctx, "init")
+
 	if len(addrNetmap) != 20 || len(addrProxy) != 20 {
 		panic("incorrect length of contract script hash")
 	}
@@ -62,19 +82,214 @@ func Init(notaryDisabled bool, owner interop.Hash160, addrNetmap, addrProxy inte
 }
 
 func Migrate(script []byte, manifest []byte) bool {
-	ctx := storage.GetReadOnlyContext()
+	ctx := storage.GetContext()
 
 	if !common.HasUpdateAccess(ctx) {
 		runtime.Log("only owner can update contract")
 		return false
 	}
 
+	common.CheckUpgrade(ctx, script, manifest)
 	management.Update(script, manifest)
 	runtime.Log("alphabet contract updated")
 
 	return true
 }
 
+// ProposeOwner proposes new contract owner. Ownership is transferred after
+// proposed owner accepts it.
+func ProposeOwner(owner interop.Hash160) bool {
+	ctx := storage.GetContext()
+	common.ProposeOwner(ctx, owner)
+
+	return true
+}
+
+// AcceptOwnership transfers contract ownership to the proposed owner.
+func AcceptOwnership() bool {
+	ctx := storage.GetContext()
+	common.AcceptOwnership(ctx)
+
+	runtime.Log("alphabet contract owner changed")
+
+	return true
+}
+
+// CancelOwnerProposal removes proposed contract owner.
+func CancelOwnerProposal() bool {
+	ctx := storage.GetContext()
+	common.CancelOwnerProposal(ctx)
+
+	return true
+}
+
+// PendingOwner returns proposed contract owner if there is one.
+func PendingOwner() interop.Hash160 {
+	ctx := storage.GetReadOnlyContext()
+	return common.PendingOwner(ctx)
+}
+
+// Migrations returns history of contract deploy and updates.
+func Migrations() []common.Migration {
+	ctx := storage.GetReadOnlyContext()
+	return common.Migrations(ctx)
+}
+
+// SetRegistry sets registry contract used to resolve addresses of other
+// NeoFS contracts. Addresses provided in Init are used if registry contract
+// can't resolve them.
+func SetRegistry(addr interop.Hash160) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		panic("setRegistry: only owner can set registry contract")
+	}
+
+	common.CheckNotGoverned(ctx, "setRegistry")
+
+	common.SetRegistry(ctx, addr)
+	runtime.Log("setRegistry: registry contract has been set")
+
+	return true
+}
+
+// SetUpgradeDelay sets amount of blocks between alphabet approval and
+// execution of contract update. Non-zero delay enables upgrade governance
+// and can be set by contract owner, after that delay can be changed by
+// alphabet nodes only.
+func SetUpgradeDelay(delay int) bool {
+	ctx := storage.GetContext()
+
+	if common.UpgradeDelay(ctx) == 0 {
+		if !common.HasUpdateAccess(ctx) {
+			panic("setUpgradeDelay: only owner can enable upgrade governance")
+		}
+	} else {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "setUpgradeDelay", []interface{}{delay}) {
+			return true
+		}
+	}
+
+	common.SetUpgradeDelay(ctx, delay)
+	runtime.Log("setUpgradeDelay: upgrade delay has been updated")
+
+	return true
+}
+
+// ProposeUpgrade proposes contract update with provided SHA256 hash of
+// concatenated SHA256 hashes of script and manifest. Available if upgrade
+// governance is enabled.
+func ProposeUpgrade(hash []byte) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		panic("proposeUpgrade: only owner can propose contract update")
+	}
+
+	common.ProposeUpgrade(ctx, hash)
+	runtime.Log("proposeUpgrade: contract update has been proposed")
+
+	return true
+}
+
+// ApproveUpgrade approves pending contract update with provided hash.
+// Approved update can be executed after upgrade delay.
+func ApproveUpgrade(hash []byte) bool {
+	ctx := storage.GetContext()
+	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+	if !common.AlphabetDecision(ctx, notaryDisabled, "approveUpgrade", []interface{}{hash}) {
+		return true
+	}
+
+	common.ApproveUpgrade(ctx, hash)
+	runtime.Log("approveUpgrade: contract update has been approved")
+
+	return true
+}
+
+// CancelUpgrade removes pending contract update. Can be invoked by the
+// contract owner or alphabet nodes.
+func CancelUpgrade() bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+		proposal := common.GetUpgradeProposal(ctx)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "cancelUpgrade", []interface{}{proposal.Hash}) {
+			return true
+		}
+	}
+
+	common.CancelUpgrade(ctx)
+	runtime.Log("cancelUpgrade: contract update has been cancelled")
+
+	return true
+}
+
+// UpgradeProposal returns pending contract update.
+func UpgradeProposal() common.UpgradeProposal {
+	ctx := storage.GetReadOnlyContext()
+	return common.GetUpgradeProposal(ctx)
+}
+
+// UpgradeDelay returns amount of blocks between alphabet approval and
+// execution of contract update. Zero value means that upgrade governance
+// is disabled.
+func UpgradeDelay() int {
+	ctx := storage.GetReadOnlyContext()
+	return common.UpgradeDelay(ctx)
+}
+
+// Pause stops execution of the state-changing contract method. If method is
+// empty, then all user-facing methods are stopped, see userMethods. Can be
+// invoked by the contract owner or alphabet nodes.
+func Pause(method string) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "pause", []interface{}{method}) {
+			return true
+		}
+	}
+
+	common.Pause(ctx, method, userMethods, systemMethods)
+	runtime.Log("pause: contract method has been paused")
+
+	return true
+}
+
+// Unpause resumes execution of the contract method stopped by Pause. Can be
+// invoked by the contract owner or alphabet nodes.
+func Unpause(method string) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "unpause", []interface{}{method}) {
+			return true
+		}
+	}
+
+	common.Unpause(ctx, method)
+	runtime.Log("unpause: contract method has been unpaused")
+
+	return true
+}
+
+// IsPaused returns true if contract method is paused. Pause of the whole
+// contract affects only user-facing methods.
+func IsPaused(method string) bool {
+	ctx := storage.GetReadOnlyContext()
+	return common.IsPaused(ctx, method, userMethods)
+}
+
 func Gas() int {
 	return gas.BalanceOf(runtime.GetExecutingScriptHash())
 }
@@ -84,7 +299,7 @@ func Neo() int {
 }
 
 func currentEpoch(ctx storage.Context) int {
-	netmapContractAddr := storage.Get(ctx, netmapKey).(interop.Hash160)
+	netmapContractAddr := common.ContractAddress(ctx, common.NetmapContractName, netmapKey)
 	return contract.Call(netmapContractAddr, "epoch", contract.ReadOnly).(int)
 }
 
@@ -114,6 +329,7 @@ func checkPermission(ir []common.IRNode) bool {
 
 func Emit() bool {
 	ctx := storage.GetReadOnlyContext()
+	common.CheckPaused(ctx, "emit", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 
 	alphabet := common.AlphabetNodes()
@@ -126,7 +342,7 @@ func Emit() bool {
 	neo.Transfer(contractHash, contractHash, neo.BalanceOf(contractHash), nil)
 
 	gasBalance := gas.BalanceOf(contractHash)
-	proxyAddr := storage.Get(ctx, proxyKey).(interop.Hash160)
+	proxyAddr := common.ContractAddress(ctx, common.ProxyContractName, proxyKey)
 
 	proxyGas := gasBalance / 2
 	if proxyGas == 0 {
@@ -140,7 +356,7 @@ func Emit() bool {
 	var innerRing []common.IRNode
 
 	if notaryDisabled {
-		netmapContract := storage.Get(ctx, netmapKey).(interop.Hash160)
+		netmapContract := common.ContractAddress(ctx, common.NetmapContractName, netmapKey)
 		innerRing = common.InnerRingNodesFromNetmap(netmapContract)
 	} else {
 		innerRing = common.InnerRingNodes()
@@ -162,6 +378,7 @@ func Emit() bool {
 
 func Vote(epoch int, candidates []interop.PublicKey) {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "vote", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 	index := index(ctx)
 	name := name(ctx)
@@ -193,11 +410,9 @@ func Vote(epoch int, candidates []interop.PublicKey) {
 	address := runtime.GetExecutingScriptHash()
 
 	if notaryDisabled {
-		threshold := len(alphabet)*2/3 + 1
 		id := voteID(epoch, candidates)
 
-		n := common.Vote(ctx, id, nodeKey)
-		if n < threshold {
+		if !common.Vote(ctx, "vote", id, nodeKey, len(alphabet)) {
 			return
 		}
 
@@ -234,6 +449,123 @@ func Name() string {
 	return name(ctx)
 }
 
+// SetNotaryDisabled switches the way to collect signatures of alphabet
+// nodes. If notary gets disabled, then ballot storage is initialized,
+// otherwise all pending ballots are removed.
+func SetNotaryDisabled(notaryDisabled bool) bool {
+	ctx := storage.GetContext()
+	currentDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+	if notaryDisabled == currentDisabled {
+		panic("setNotaryDisabled: notary mode is already set")
+	}
+
+	if !common.AlphabetDecision(ctx, currentDisabled, "setNotaryDisabled", []interface{}{}) {
+		return true
+	}
+
+	storage.Put(ctx, notaryDisabledKey, notaryDisabled)
+	if notaryDisabled {
+		common.InitVote(ctx)
+		runtime.Log("setNotaryDisabled: notary disabled")
+	} else {
+		common.PurgeVotes(ctx)
+		runtime.Log("setNotaryDisabled: notary enabled")
+	}
+
+	runtime.Notify("NotaryModeChanged", notaryDisabled)
+
+	return true
+}
+
+// RevokeVote removes vote of the invoking alphabet node from the pending
+// ballot with provided ID, if the decision has not reached quorum yet.
+func RevokeVote(id []byte) bool {
+	ctx := storage.GetContext()
+
+	alphabet := common.AlphabetNodes()
+	nodeKey := common.InnerRingInvoker(alphabet)
+	if len(nodeKey) == 0 {
+		panic("revokeVote: this method must be invoked by alphabet nodes")
+	}
+
+	if !common.RevokeVote(ctx, id, nodeKey) {
+		panic("revokeVote: there is no pending vote of the invoker")
+	}
+
+	runtime.Notify("VoteRevoked", id, nodeKey)
+	runtime.Log("revokeVote: vote has been revoked")
+
+	return true
+}
+
+// ListBallots returns list of pending ballots of the decisions made
+// without notary.
+func ListBallots() []common.BallotState {
+	ctx := storage.GetReadOnlyContext()
+	return common.Ballots(ctx)
+}
+
+// PurgeBallots removes at most limit expired ballots of the decisions made
+// without notary and returns amount of removed ballots. Only expired ballots
+// are removed, so method can be invoked by anyone.
+func PurgeBallots(limit int) int {
+	ctx := storage.GetContext()
+
+	if limit <= 0 {
+		panic("purgeBallots: invalid limit")
+	}
+
+	return common.PurgeExpiredVotes(ctx, limit)
+}
+
+// GetBallot returns pending ballot of the decision with provided ID.
+func GetBallot(id []byte) common.BallotState {
+	ctx := storage.GetReadOnlyContext()
+	return common.GetBallot(ctx, id)
+}
+
+// SetVotePolicy sets ballot expiry in blocks and quorum fraction of the
+// decisions made by contract method without notary. If method is empty,
+// then policy is set for all contract methods without own policy.
+func SetVotePolicy(method string, expiry, numerator, denominator int) bool {
+	ctx := storage.GetContext()
+	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+	policy := common.VotePolicy{
+		Expiry:      expiry,
+		Numerator:   numerator,
+		Denominator: denominator,
+	}
+
+	if !common.ValidVotePolicy(policy) {
+		panic("setVotePolicy: invalid vote policy")
+	}
+
+	if !common.AlphabetDecision(ctx, notaryDisabled, "setVotePolicy", []interface{}{method, expiry, numerator, denominator}) {
+		return true
+	}
+
+	common.SetVotePolicy(ctx, method, policy)
+	runtime.Log("setVotePolicy: vote policy has been updated")
+
+	return true
+}
+
+// VotePolicy returns vote policy of the decisions made by contract method
+// without notary.
+func VotePolicy(method string) common.VotePolicy {
+	ctx := storage.GetReadOnlyContext()
+	return common.GetVotePolicy(ctx, method)
+}
+
 func Version() int {
 	return version
 }
+
+// migrateStorage converts contract storage of the previous version to the
+// actual layout.
+func migrateStorage(ctx storage.Context, prev int) {
+	if prev < 2 {
+		// ballots are stored under their own keys since version 2
+		common.InitVote(ctx)
+	}
+}
@@ -42,6 +42,20 @@ const (
 	notaryDisabledKey = "notary"
 )
 
+// Pausable methods of the contract, see Pause.
+var (
+	// userMethods are stopped by the pause of the whole contract.
+	userMethods = []string{}
+	// systemMethods are invoked by inner ring nodes, so they are stopped
+	// only by their own pause.
+	systemMethods = []string{"put"}
+)
+
+func _deploy(data interface{}, isUpdate bool) {
+	ctx := storage.GetContext()
+	common.CommitVersion(ctx, version)
+}
+
 func Init(notaryDisabled bool, owner interop.Hash160, addrNetmap interop.Hash160) {
 	ctx := storage.GetContext()
 
@@ -49,6 +63,8 @@ func Init(notaryDisabled bool, owner interop.Hash160, addrNetmap interop.Hash160
 		panic("only owner can reinitialize contract")
 	}
 
+	common.CheckNotGoverned(ctx, "init")
+
 	if len(addrNetmap) != 20 {
 		panic("init: incorrect length of contract script hash")
 	}
@@ -66,27 +82,223 @@ func Init(notaryDisabled bool, owner interop.Hash160, addrNetmap interop.Hash160
 }
 
 func Migrate(script []byte, manifest []byte) bool {
-	ctx := storage.GetReadOnlyContext()
+	ctx := storage.GetContext()
 
 	if !common.HasUpdateAccess(ctx) {
 		runtime.Log("only owner can update contract")
 		return false
 	}
 
+	common.CheckUpgrade(ctx, script, manifest)
 	management.Update(script, manifest)
 	runtime.Log("audit contract updated")
 
 	return true
 }
 
+// ProposeOwner proposes new contract owner. Ownership is transferred after
+// proposed owner accepts it.
+func ProposeOwner(owner interop.Hash160) bool {
+	ctx := storage.GetContext()
+	common.ProposeOwner(ctx, owner)
+
+	return true
+}
+
+// AcceptOwnership transfers contract ownership to the proposed owner.
+func AcceptOwnership() bool {
+	ctx := storage.GetContext()
+	common.AcceptOwnership(ctx)
+
+	runtime.Log("audit contract owner changed")
+
+	return true
+}
+
+// CancelOwnerProposal removes proposed contract owner.
+func CancelOwnerProposal() bool {
+	ctx := storage.GetContext()
+	common.CancelOwnerProposal(ctx)
+
+	return true
+}
+
+// PendingOwner returns proposed contract owner if there is one.
+func PendingOwner() interop.Hash160 {
+	ctx := storage.GetReadOnlyContext()
+	return common.PendingOwner(ctx)
+}
+
+// Migrations returns history of contract deploy and updates.
+func Migrations() []common.Migration {
+	ctx := storage.GetReadOnlyContext()
+	return common.Migrations(ctx)
+}
+
+// SetRegistry sets registry contract used to resolve addresses of other
+// NeoFS contracts. Addresses provided in Init are used if registry contract
+// can't resolve them.
+func SetRegistry(addr interop.Hash160) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		panic("setRegistry: only owner can set registry contract")
+	}
+
+	common.CheckNotGoverned(ctx, "setRegistry")
+
+	common.SetRegistry(ctx, addr)
+	runtime.Log("setRegistry: registry contract has been set")
+
+	return true
+}
+
+// SetUpgradeDelay sets amount of blocks between alphabet approval and
+// execution of contract update. Non-zero delay enables upgrade governance
+// and can be set by contract owner, after that delay can be changed by
+// alphabet nodes only.
+func SetUpgradeDelay(delay int) bool {
+	ctx := storage.GetContext()
+
+	if common.UpgradeDelay(ctx) == 0 {
+		if !common.HasUpdateAccess(ctx) {
+			panic("setUpgradeDelay: only owner can enable upgrade governance")
+		}
+	} else {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "setUpgradeDelay", []interface{}{delay}) {
+			return true
+		}
+	}
+
+	common.SetUpgradeDelay(ctx, delay)
+	runtime.Log("setUpgradeDelay: upgrade delay has been updated")
+
+	return true
+}
+
+// ProposeUpgrade proposes contract update with provided SHA256 hash of
+// concatenated SHA256 hashes of script and manifest. Available if upgrade
+// governance is enabled.
+func ProposeUpgrade(hash []byte) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		panic("proposeUpgrade: only owner can propose contract update")
+	}
+
+	common.ProposeUpgrade(ctx, hash)
+	runtime.Log("proposeUpgrade: contract update has been proposed")
+
+	return true
+}
+
+// ApproveUpgrade approves pending contract update with provided hash.
+// Approved update can be executed after upgrade delay.
+func ApproveUpgrade(hash []byte) bool {
+	ctx := storage.GetContext()
+	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+	if !common.AlphabetDecision(ctx, notaryDisabled, "approveUpgrade", []interface{}{hash}) {
+		return true
+	}
+
+	common.ApproveUpgrade(ctx, hash)
+	runtime.Log("approveUpgrade: contract update has been approved")
+
+	return true
+}
+
+// CancelUpgrade removes pending contract update. Can be invoked by the
+// contract owner or alphabet nodes.
+func CancelUpgrade() bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+		proposal := common.GetUpgradeProposal(ctx)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "cancelUpgrade", []interface{}{proposal.Hash}) {
+			return true
+		}
+	}
+
+	common.CancelUpgrade(ctx)
+	runtime.Log("cancelUpgrade: contract update has been cancelled")
+
+	return true
+}
+
+// UpgradeProposal returns pending contract update.
+func UpgradeProposal() common.UpgradeProposal {
+	ctx := storage.GetReadOnlyContext()
+	return common.GetUpgradeProposal(ctx)
+}
+
+// UpgradeDelay returns amount of blocks between alphabet approval and
+// execution of contract update. Zero value means that upgrade governance
+// is disabled.
+func UpgradeDelay() int {
+	ctx := storage.GetReadOnlyContext()
+	return common.UpgradeDelay(ctx)
+}
+
+// Pause stops execution of the state-changing contract method. If method is
+// empty, then all user-facing methods are stopped, see userMethods. Can be
+// invoked by the contract owner or alphabet nodes.
+func Pause(method string) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "pause", []interface{}{method}) {
+			return true
+		}
+	}
+
+	common.Pause(ctx, method, userMethods, systemMethods)
+	runtime.Log("pause: contract method has been paused")
+
+	return true
+}
+
+// Unpause resumes execution of the contract method stopped by Pause. Can be
+// invoked by the contract owner or alphabet nodes.
+func Unpause(method string) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "unpause", []interface{}{method}) {
+			return true
+		}
+	}
+
+	common.Unpause(ctx, method)
+	runtime.Log("unpause: contract method has been unpaused")
+
+	return true
+}
+
+// IsPaused returns true if contract method is paused. Pause of the whole
+// contract affects only user-facing methods.
+func IsPaused(method string) bool {
+	ctx := storage.GetReadOnlyContext()
+	return common.IsPaused(ctx, method, userMethods)
+}
+
 func Put(rawAuditResult []byte) bool {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "put", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 
 	var innerRing []common.IRNode
 
 	if notaryDisabled {
-		netmapContract := storage.Get(ctx, netmapContractKey).(interop.Hash160)
+		netmapContract := common.ContractAddress(ctx, common.NetmapContractName, netmapContractKey)
 		innerRing = common.InnerRingNodesFromNetmap(netmapContract)
 	} else {
 		innerRing = common.InnerRingNodes()
@@ -159,18 +371,129 @@ func ListByNode(epoch int, cid []byte, key interop.PublicKey) [][]byte {
 	return list(it)
 }
 
+// SetNotaryDisabled switches the way to collect signatures of alphabet
+// nodes. If notary gets disabled, then ballot storage is initialized,
+// otherwise all pending ballots are removed.
+func SetNotaryDisabled(notaryDisabled bool) bool {
+	ctx := storage.GetContext()
+	currentDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+	if notaryDisabled == currentDisabled {
+		panic("setNotaryDisabled: notary mode is already set")
+	}
+
+	if !common.AlphabetDecision(ctx, currentDisabled, "setNotaryDisabled", []interface{}{}) {
+		return true
+	}
+
+	storage.Put(ctx, notaryDisabledKey, notaryDisabled)
+	if notaryDisabled {
+		common.InitVote(ctx)
+		runtime.Log("setNotaryDisabled: notary disabled")
+	} else {
+		common.PurgeVotes(ctx)
+		runtime.Log("setNotaryDisabled: notary enabled")
+	}
+
+	runtime.Notify("NotaryModeChanged", notaryDisabled)
+
+	return true
+}
+
+// RevokeVote removes vote of the invoking alphabet node from the pending
+// ballot with provided ID, if the decision has not reached quorum yet.
+func RevokeVote(id []byte) bool {
+	ctx := storage.GetContext()
+
+	alphabet := common.AlphabetNodes()
+	nodeKey := common.InnerRingInvoker(alphabet)
+	if len(nodeKey) == 0 {
+		panic("revokeVote: this method must be invoked by alphabet nodes")
+	}
+
+	if !common.RevokeVote(ctx, id, nodeKey) {
+		panic("revokeVote: there is no pending vote of the invoker")
+	}
+
+	runtime.Notify("VoteRevoked", id, nodeKey)
+	runtime.Log("revokeVote: vote has been revoked")
+
+	return true
+}
+
+// ListBallots returns list of pending ballots of the decisions made
+// without notary.
+func ListBallots() []common.BallotState {
+	ctx := storage.GetReadOnlyContext()
+	return common.Ballots(ctx)
+}
+
+// PurgeBallots removes at most limit expired ballots of the decisions made
+// without notary and returns amount of removed ballots. Only expired ballots
+// are removed, so method can be invoked by anyone.
+func PurgeBallots(limit int) int {
+	ctx := storage.GetContext()
+
+	if limit <= 0 {
+		panic("purgeBallots: invalid limit")
+	}
+
+	return common.PurgeExpiredVotes(ctx, limit)
+}
+
+// GetBallot returns pending ballot of the decision with provided ID.
+func GetBallot(id []byte) common.BallotState {
+	ctx := storage.GetReadOnlyContext()
+	return common.GetBallot(ctx, id)
+}
+
+// SetVotePolicy sets ballot expiry in blocks and quorum fraction of the
+// decisions made by contract method without notary. If method is empty,
+// then policy is set for all contract methods without own policy.
+func SetVotePolicy(method string, expiry, numerator, denominator int) bool {
+	ctx := storage.GetContext()
+	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+	policy := common.VotePolicy{
+		Expiry:      expiry,
+		Numerator:   numerator,
+		Denominator: denominator,
+	}
+
+	if !common.ValidVotePolicy(policy) {
+		panic("setVotePolicy: invalid vote policy")
+	}
+
+	if !common.AlphabetDecision(ctx, notaryDisabled, "setVotePolicy", []interface{}{method, expiry, numerator, denominator}) {
+		return true
+	}
+
+	common.SetVotePolicy(ctx, method, policy)
+	runtime.Log("setVotePolicy: vote policy has been updated")
+
+	return true
+}
+
+// VotePolicy returns vote policy of the decisions made by contract method
+// without notary.
+func VotePolicy(method string) common.VotePolicy {
+	ctx := storage.GetReadOnlyContext()
+	return common.GetVotePolicy(ctx, method)
+}
+
 func list(it iterator.Iterator) [][]byte {
 	var result [][]byte
 
 	ignore := [][]byte{
 		[]byte(netmapContractKey),
-		[]byte(common.OwnerKey),
 		[]byte(notaryDisabledKey),
 	}
 
 loop:
 	for iterator.Next(it) {
 		key := iterator.Value(it).([]byte) // iterator MUST BE `storage.KeysOnly`
+		if common.IsServiceKey(key) {
+			continue
+		}
+
 		for _, ignoreKey := range ignore {
 			if common.BytesEqual(key, ignoreKey) {
 				continue loop
@@ -187,30 +510,8 @@ func Version() int {
 	return version
 }
 
-// readNext reads length from first byte and then reads data (max 127 bytes).
-func readNext(input []byte) ([]byte, int) {
-	var buf interface{} = input[0]
-	ln := buf.(int)
-
-	return input[1 : 1+ln], 1 + ln
-}
-
 func newAuditHeader(input []byte) auditHeader {
-	offset := int(input[1])
-	offset = 2 + offset + 1 // version prefix + version len + epoch prefix
-
-	var buf interface{} = input[offset : offset+8] // [ 8 integer bytes ]
-	epoch := buf.(int)
-
-	offset = offset + 8
-
-	// cid is a nested structure with raw bytes
-	// [ cid struct prefix (wireType + len = 2 bytes), cid value wireType (1 byte), ... ]
-	cid, cidOffset := readNext(input[offset+2+1:])
-
-	// key is a raw byte
-	// [ public key wireType (1 byte), ... ]
-	key, _ := readNext(input[offset+2+1+cidOffset+1:])
+	epoch, cid, key := common.AuditResultHeader(input)
 
 	return auditHeader{
 		epoch,
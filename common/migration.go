@@ -0,0 +1,72 @@
+package common
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/interop"
+	"github.com/nspcc-dev/neo-go/pkg/interop/native/ledger"
+	"github.com/nspcc-dev/neo-go/pkg/interop/native/std"
+	"github.com/nspcc-dev/neo-go/pkg/interop/runtime"
+	"github.com/nspcc-dev/neo-go/pkg/interop/storage"
+)
+
+// Migration describes single deploy or update of the contract.
+type Migration struct {
+	// Version of the contract before update, 0 for the initial deploy and
+	// for the contracts that did not record their version.
+	From int
+
+	// Version of the deployed contract.
+	To int
+
+	// Height of block with deploy or update transaction.
+	Height int
+
+	// Hash of deploy or update transaction.
+	TxHash interop.Hash256
+}
+
+const (
+	VersionKey    = "contractVersion"
+	MigrationsKey = "migrations"
+)
+
+// CommitVersion must be called from `_deploy` method of the contract. It
+// panics if contract of 'version' is going to replace contract of higher
+// version, otherwise it stores new version, records migration in contract
+// history and returns version of the replaced contract.
+func CommitVersion(ctx storage.Context, version int) int {
+	prev := 0
+
+	data := storage.Get(ctx, VersionKey)
+	if data != nil {
+		prev = data.(int)
+	}
+
+	if version < prev {
+		panic("contract downgrade is not allowed")
+	}
+
+	tx := runtime.GetScriptContainer()
+
+	history := Migrations(ctx)
+	history = append(history, Migration{
+		From:   prev,
+		To:     version,
+		Height: ledger.CurrentIndex(),
+		TxHash: tx.Hash,
+	})
+
+	storage.Put(ctx, VersionKey, version)
+	SetSerialized(ctx, MigrationsKey, history)
+
+	return prev
+}
+
+// Migrations returns history of contract deploy and updates.
+func Migrations(ctx storage.Context) []Migration {
+	data := storage.Get(ctx, MigrationsKey)
+	if data != nil {
+		return std.Deserialize(data.([]byte)).([]Migration)
+	}
+
+	return []Migration{}
+}
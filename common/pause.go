@@ -0,0 +1,60 @@
+package common
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/interop/runtime"
+	"github.com/nspcc-dev/neo-go/pkg/interop/storage"
+)
+
+const pausePrefix = "paused"
+
+// Pause stops execution of the contract 'method'. If method is empty, then
+// all user-facing methods of the contract are stopped, methods invoked by
+// inner ring nodes can be paused only by name. Panics if method is neither
+// one of 'userMethods' nor one of 'systemMethods' of the contract.
+func Pause(ctx storage.Context, method string, userMethods, systemMethods []string) {
+	if len(method) != 0 && !inList(userMethods, method) && !inList(systemMethods, method) {
+		panic("pause: method can't be paused")
+	}
+
+	storage.Put(ctx, pausePrefix+method, true)
+
+	runtime.Notify("Paused", method)
+}
+
+// Unpause resumes execution of the contract 'method' stopped by Pause.
+func Unpause(ctx storage.Context, method string) {
+	storage.Delete(ctx, pausePrefix+method)
+
+	runtime.Notify("Unpaused", method)
+}
+
+// IsPaused returns true if contract 'method' is paused. Pause of the whole
+// contract is taken into account only if method is one of 'userMethods' or
+// if method is empty.
+func IsPaused(ctx storage.Context, method string, userMethods []string) bool {
+	if len(method) == 0 || inList(userMethods, method) {
+		if storage.Get(ctx, pausePrefix) != nil {
+			return true
+		}
+	}
+
+	return len(method) != 0 && storage.Get(ctx, pausePrefix+method) != nil
+}
+
+// CheckPaused panics if contract 'method' is paused, see IsPaused. Must be
+// called at the beginning of the pausable contract methods.
+func CheckPaused(ctx storage.Context, method string, userMethods []string) {
+	if IsPaused(ctx, method, userMethods) {
+		panic(method + ": method is paused")
+	}
+}
+
+func inList(list []string, s string) bool {
+	for i := range list {
+		if list[i] == s {
+			return true
+		}
+	}
+
+	return false
+}
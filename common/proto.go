@@ -0,0 +1,308 @@
+package common
+
+import "github.com/nspcc-dev/neo-go/pkg/interop"
+
+// SessionToken describes container session token of NeoFS API.
+type SessionToken struct {
+	// Marshaled token body, token signature is made for it.
+	Body []byte
+
+	// Owner ID of the token issuer.
+	OwnerID []byte
+
+	// Container operation allowed by the token, see SessionVerbPut,
+	// SessionVerbDelete and SessionVerbSetEACL.
+	Verb int
+
+	// ID of the container the token is issued for, empty if the token
+	// is issued for all containers of the owner.
+	ContainerID []byte
+
+	// Token is valid from NotBefore to Expiration epoch inclusive.
+	NotBefore  int
+	Expiration int
+
+	// Public key delegated by the token.
+	SessionKey interop.PublicKey
+
+	// Public key of the token signature, empty if token is not signed.
+	SignerKey interop.PublicKey
+
+	// Scheme of the token signature, see SignatureECDSASHA512 and
+	// SignatureECDSARFC6979.
+	SignatureScheme int
+}
+
+// Container operations of the session token.
+const (
+	SessionVerbPut     = 1
+	SessionVerbDelete  = 2
+	SessionVerbSetEACL = 3
+)
+
+// Signature schemes of NeoFS API.
+const (
+	SignatureECDSASHA512  = 0
+	SignatureECDSARFC6979 = 1
+)
+
+// Protobuf wire types used in NeoFS API messages.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+const (
+	ownerIDSize     = 25
+	containerIDSize = 32
+	publicKeySize   = 33
+)
+
+// ReadVarint returns protobuf varint value at the offset of the data and
+// offset of the next byte after it.
+func ReadVarint(data []byte, offset int) (int, int) {
+	value := 0
+	shift := 1
+
+	for {
+		if offset >= len(data) {
+			panic("proto: unexpected end of varint")
+		}
+
+		b := int(data[offset])
+		offset++
+
+		if b < 0x80 {
+			return value + b*shift, offset
+		}
+
+		value += (b - 0x80) * shift
+		shift *= 0x80
+	}
+}
+
+// Fields returns values and wire types of all occurrences of the field in
+// protobuf message. Varint and fixed values are returned as integers,
+// length-delimited values are returned as byte slices.
+func Fields(data []byte, field int) ([]interface{}, []int) {
+	var (
+		result []interface{}
+		types  []int
+	)
+
+	for i := 0; i < len(data); {
+		tag, next := ReadVarint(data, i)
+		i = next
+
+		var value interface{}
+
+		switch tag & 7 {
+		case wireVarint:
+			value, i = ReadVarint(data, i)
+		case wireFixed64, wireFixed32:
+			size := 8
+			if tag&7 == wireFixed32 {
+				size = 4
+			}
+
+			if i+size > len(data) {
+				panic("proto: unexpected end of fixed field")
+			}
+
+			var buf interface{} = data[i : i+size]
+			value = buf.(int)
+			i += size
+		case wireBytes:
+			size, start := ReadVarint(data, i)
+			i = start + size
+
+			if i > len(data) {
+				panic("proto: unexpected end of length-delimited field")
+			}
+
+			value = data[start:i]
+		default:
+			panic("proto: unsupported wire type")
+		}
+
+		if tag>>3 == field {
+			result = append(result, value)
+			types = append(types, tag&7)
+		}
+	}
+
+	return result, types
+}
+
+// BytesFields returns values of all occurrences of length-delimited field in
+// protobuf message. Panics if the field has another wire type.
+func BytesFields(data []byte, field int) [][]byte {
+	var result [][]byte
+
+	values, types := Fields(data, field)
+	for i := 0; i < len(values); i++ {
+		if types[i] != wireBytes {
+			panic("proto: unexpected wire type")
+		}
+
+		result = append(result, values[i].([]byte))
+	}
+
+	return result
+}
+
+// BytesField returns value of the first occurrence of length-delimited field
+// in protobuf message or nil if there is no such field. Panics if the field
+// has another wire type.
+func BytesField(data []byte, field int) []byte {
+	values := BytesFields(data, field)
+	if len(values) == 0 {
+		return nil
+	}
+
+	return values[0]
+}
+
+// IntField returns value of the first occurrence of varint or fixed field in
+// protobuf message or zero if there is no such field. Panics if the field is
+// length-delimited.
+func IntField(data []byte, field int) int {
+	values, types := Fields(data, field)
+	if len(values) == 0 {
+		return 0
+	}
+
+	if types[0] == wireBytes {
+		panic("proto: unexpected wire type")
+	}
+
+	return values[0].(int)
+}
+
+// ContainerOwnerID returns owner ID of the container structure.
+func ContainerOwnerID(container []byte) []byte {
+	ownerID := BytesField(BytesField(container, 2), 1)
+	if len(ownerID) != ownerIDSize {
+		panic("proto: invalid container owner ID")
+	}
+
+	return ownerID
+}
+
+// ContainerAttribute returns value of the container attribute with the key
+// or empty string if there is no such attribute.
+func ContainerAttribute(container []byte, key string) string {
+	attributes := BytesFields(container, 5)
+	for i := 0; i < len(attributes); i++ {
+		attribute := attributes[i]
+		if string(BytesField(attribute, 1)) == key {
+			return string(BytesField(attribute, 2))
+		}
+	}
+
+	return ""
+}
+
+// ContainerReplicas returns total amount of object replicas in the container
+// placement policy.
+func ContainerReplicas(container []byte) int {
+	count := 0
+
+	replicas := BytesFields(BytesField(container, 6), 1)
+	for i := 0; i < len(replicas); i++ {
+		count += IntField(replicas[i], 1)
+	}
+
+	return count
+}
+
+// EACLContainerID returns container ID of the eACL table.
+func EACLContainerID(eACL []byte) []byte {
+	containerID := BytesField(BytesField(eACL, 2), 1)
+	if len(containerID) != containerIDSize {
+		panic("proto: invalid eACL container ID")
+	}
+
+	return containerID
+}
+
+// NodeInfoKey returns public key of the node info structure.
+func NodeInfoKey(nodeInfo []byte) []byte {
+	key := BytesField(nodeInfo, 1)
+	if len(key) != publicKeySize {
+		panic("proto: invalid node info public key")
+	}
+
+	return key
+}
+
+// AuditResultHeader returns epoch, container ID and inner ring node public
+// key of the data audit result structure.
+func AuditResultHeader(result []byte) (int, []byte, []byte) {
+	containerID := BytesField(BytesField(result, 3), 1)
+	if len(containerID) != containerIDSize {
+		panic("proto: invalid audit result container ID")
+	}
+
+	key := BytesField(result, 4)
+	if len(key) != publicKeySize {
+		panic("proto: invalid audit result public key")
+	}
+
+	return IntField(result, 2), containerID, key
+}
+
+// ContainerSessionToken returns container session token from its protobuf
+// representation. Token layout follows session.SessionToken message of NeoFS
+// API v2.11, the first version with signature scheme in refs.Signature.
+// Panics if token is not a container session token.
+func ContainerSessionToken(token []byte) SessionToken {
+	body := BytesField(token, 1)
+
+	ownerID := BytesField(BytesField(body, 2), 1)
+	if len(ownerID) != ownerIDSize {
+		panic("proto: invalid session token owner ID")
+	}
+
+	sessionKey := BytesField(body, 4)
+	if len(sessionKey) != publicKeySize {
+		panic("proto: invalid session token key")
+	}
+
+	containerContext := BytesField(body, 6)
+	if containerContext == nil {
+		panic("proto: session token is not a container session token")
+	}
+
+	containerID := []byte{}
+	if IntField(containerContext, 2) == 0 { // not a wildcard
+		containerID = BytesField(BytesField(containerContext, 3), 1)
+		if len(containerID) != containerIDSize {
+			panic("proto: invalid session token container ID")
+		}
+	}
+
+	lifetime := BytesField(body, 3)
+
+	signature := BytesField(token, 2)
+
+	signerKey := BytesField(signature, 1)
+	if signerKey == nil {
+		signerKey = []byte{}
+	}
+
+	return SessionToken{
+		Body:        body,
+		OwnerID:     ownerID,
+		Verb:        IntField(containerContext, 1),
+		ContainerID: containerID,
+		NotBefore:   IntField(lifetime, 2),
+		Expiration:  IntField(lifetime, 1),
+		SessionKey:  sessionKey,
+		SignerKey:   signerKey,
+
+		SignatureScheme: IntField(signature, 3),
+	}
+}
@@ -0,0 +1,95 @@
+package common
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// sessionTokenFixture is a container session token of NeoFS API v2.11 with
+// PUT verb for a single container, valid from 10 to 20 epoch. Token body is
+// signed by the owner key with ECDSA_RFC6979_SHA256 scheme.
+const sessionTokenFixture = "0a82010a108d4c1e2a9b6f4d0aa13e5f771290c43b121b0a1935aab9a5f62eae868613b87da3" +
+	"9890b0f47fd5a45ee60996ae1a060814100a180a22210278e2c718619ff9509abacc4acc50751333c6d49a7d1a4c48f52677" +
+	"0b03a6ef90322608011a220a20a42d519714d616e9411dbceec4b52808bd6b1ee53e6f6497a281d655357d8b7112670a2103" +
+	"1a6c6fbbdf02ca351745fa86b9ba5a9452d785ac4f7fc2b7548ca2a46c4fcf4a1240a938b657226d8ff2064fd474f3cc42b2" +
+	"1886d85166422977cc08d9cb53f3b893383969eeca6475ae4fb554dcb53dd40b293b592d93e9d9a0bed1b5d5d3322fdd1801"
+
+func TestContainerSessionToken(t *testing.T) {
+	token := fromHex(t, sessionTokenFixture)
+	session := ContainerSessionToken(token)
+
+	expectBytes(t, "owner ID", session.OwnerID, "35aab9a5f62eae868613b87da39890b0f47fd5a45ee60996ae")
+	expectBytes(t, "container ID", session.ContainerID, "a42d519714d616e9411dbceec4b52808bd6b1ee53e6f6497a281d655357d8b71")
+	expectBytes(t, "session key", session.SessionKey, "0278e2c718619ff9509abacc4acc50751333c6d49a7d1a4c48f526770b03a6ef90")
+	expectBytes(t, "signer key", session.SignerKey, "031a6c6fbbdf02ca351745fa86b9ba5a9452d785ac4f7fc2b7548ca2a46c4fcf4a")
+
+	if session.Verb != SessionVerbPut {
+		t.Errorf("verb: expected %d, got %d", SessionVerbPut, session.Verb)
+	}
+
+	if session.NotBefore != 10 || session.Expiration != 20 {
+		t.Errorf("lifetime: expected [10, 20], got [%d, %d]", session.NotBefore, session.Expiration)
+	}
+
+	if session.SignatureScheme != SignatureECDSARFC6979 {
+		t.Errorf("signature scheme: expected %d, got %d", SignatureECDSARFC6979, session.SignatureScheme)
+	}
+
+	// body is the signed part of the token, verify it the same way as
+	// CryptoLib does: ECDSA over SHA-256 with 64-byte r||s signature
+	sig := BytesField(BytesField(token, 2), 2)
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), session.SignerKey)
+	key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	hash := sha256.Sum256(session.Body)
+
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(key, hash[:], r, s) {
+		t.Error("token body signature is not valid")
+	}
+}
+
+func TestFieldWireType(t *testing.T) {
+	// field 1 is varint 150, field 2 is length-delimited "testing"
+	msg := fromHex(t, "089601120774657374696e67")
+
+	if v := IntField(msg, 1); v != 150 {
+		t.Errorf("int field: expected 150, got %d", v)
+	}
+
+	if v := BytesField(msg, 2); string(v) != "testing" {
+		t.Errorf("bytes field: expected testing, got %s", v)
+	}
+
+	expectPanic(t, "proto: unexpected wire type", func() { BytesField(msg, 1) })
+	expectPanic(t, "proto: unexpected wire type", func() { IntField(msg, 2) })
+}
+
+func fromHex(t *testing.T, s string) []byte {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func expectBytes(t *testing.T, name string, actual []byte, expected string) {
+	if !bytes.Equal(actual, fromHex(t, expected)) {
+		t.Errorf("%s: expected %s, got %x", name, expected, actual)
+	}
+}
+
+func expectPanic(t *testing.T, msg string, f func()) {
+	defer func() {
+		if r := recover(); r != msg {
+			t.Errorf("expected panic %q, got %v", msg, r)
+		}
+	}()
+
+	f()
+}
@@ -0,0 +1,75 @@
+package common
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/interop"
+	"github.com/nspcc-dev/neo-go/pkg/interop/contract"
+	"github.com/nspcc-dev/neo-go/pkg/interop/storage"
+)
+
+// Well-known names of the side chain contracts in registry contract.
+const (
+	AuditContractName      = "audit"
+	BalanceContractName    = "balance"
+	ContainerContractName  = "container"
+	NeoFSIDContractName    = "neofsid"
+	NetmapContractName     = "netmap"
+	ProxyContractName      = "proxy"
+	ReputationContractName = "reputation"
+)
+
+const (
+	RegistryKey = "registryScriptHash"
+
+	resolveMethod = "resolve"
+)
+
+// SetRegistry stores script hash of the registry contract used to resolve
+// addresses of other contracts.
+func SetRegistry(ctx storage.Context, addr interop.Hash160) {
+	if len(addr) != 20 {
+		panic("setRegistry: incorrect length of contract script hash")
+	}
+
+	storage.Put(ctx, RegistryKey, addr)
+}
+
+// resolvedAddress is a contract address resolved through registry contract.
+type resolvedAddress struct {
+	name string
+	addr interop.Hash160
+}
+
+// resolvedAddresses caches contract addresses resolved in the current
+// invocation. Global variables are initialized on every contract invocation,
+// so registry contract is called at most once per name in the invocation.
+var resolvedAddresses = []resolvedAddress{}
+
+// ContractAddress returns script hash of the contract with well-known 'name'
+// resolved through registry contract. If registry contract is not set or
+// it does not know the name, then script hash stored under 'key' is
+// returned.
+func ContractAddress(ctx storage.Context, name, key string) interop.Hash160 {
+	for i := range resolvedAddresses {
+		if resolvedAddresses[i].name == name {
+			return resolvedAddresses[i].addr
+		}
+	}
+
+	var addr interop.Hash160
+
+	registry := storage.Get(ctx, RegistryKey)
+	if registry != nil {
+		resolved := contract.Call(registry.(interop.Hash160), resolveMethod, contract.ReadOnly, name)
+		if resolved != nil {
+			addr = resolved.(interop.Hash160)
+		}
+	}
+
+	if addr == nil {
+		addr = storage.Get(ctx, key).(interop.Hash160)
+	}
+
+	resolvedAddresses = append(resolvedAddresses, resolvedAddress{name: name, addr: addr})
+
+	return addr
+}
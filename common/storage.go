@@ -19,3 +19,36 @@ func SetSerialized(ctx storage.Context, key interface{}, value interface{}) {
 	data := std.Serialize(value)
 	storage.Put(ctx, key, data)
 }
+
+// IsServiceKey returns true if storage key is used by common contract
+// routines: ownership, versioning, upgrade governance, registry, ballots,
+// vote policies and pauses. Used to filter such keys out while iterating over
+// contract storage.
+func IsServiceKey(key []byte) bool {
+	keys := []string{
+		OwnerKey,
+		PendingOwnerKey,
+		VersionKey,
+		MigrationsKey,
+		UpgradeDelayKey,
+		UpgradeProposalKey,
+		RegistryKey,
+	}
+
+	for i := range keys {
+		if BytesEqual(key, []byte(keys[i])) {
+			return true
+		}
+	}
+
+	prefixes := []string{voteKey, policyKey, pausePrefix}
+
+	for i := range prefixes {
+		prefix := prefixes[i]
+		if len(key) >= len(prefix) && BytesEqual(key[:len(prefix)], []byte(prefix)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -6,6 +6,8 @@ var (
 	lockPrefix         = []byte{0x03}
 	unlockPrefix       = []byte{0x04}
 	containerFeePrefix = []byte{0x10}
+	deleteFeePrefix    = []byte{0x11}
+	refundPrefix       = []byte{0x12}
 )
 
 func WalletToScriptHash(wallet []byte) []byte {
@@ -32,3 +34,11 @@ func UnlockTransferDetails(epoch int) []byte {
 func ContainerFeeTransferDetails(cid []byte) []byte {
 	return append(containerFeePrefix, cid...)
 }
+
+func ContainerDeleteFeeTransferDetails(cid []byte) []byte {
+	return append(deleteFeePrefix, cid...)
+}
+
+func ContainerRefundTransferDetails(cid []byte) []byte {
+	return append(refundPrefix, cid...)
+}
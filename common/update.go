@@ -6,7 +6,10 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/interop/storage"
 )
 
-const OwnerKey = "contractOwner"
+const (
+	OwnerKey        = "contractOwner"
+	PendingOwnerKey = "pendingOwner"
+)
 
 // HasUpdateAccess returns true if contract can be initialized, re-initialized
 // or migrated.
@@ -20,3 +23,71 @@ func HasUpdateAccess(ctx storage.Context) bool {
 
 	return runtime.CheckWitness(owner)
 }
+
+// ProposeOwner stores new contract owner candidate. Ownership is not
+// transferred until candidate accepts it with AcceptOwnership. Panics if
+// contract is not initialized or invoker is not current contract owner.
+func ProposeOwner(ctx storage.Context, owner interop.Hash160) {
+	if len(owner) != 20 {
+		panic("proposeOwner: incorrect length of owner script hash")
+	}
+
+	current := storage.Get(ctx, OwnerKey)
+	if current == nil || !runtime.CheckWitness(current.(interop.Hash160)) {
+		panic("proposeOwner: only owner can propose new owner")
+	}
+
+	storage.Put(ctx, PendingOwnerKey, owner)
+
+	runtime.Notify("OwnerProposed", current, owner)
+}
+
+// AcceptOwnership makes proposed owner candidate the contract owner. Panics
+// if there is no candidate or invoker is not the candidate.
+func AcceptOwnership(ctx storage.Context) {
+	pending := storage.Get(ctx, PendingOwnerKey)
+	if pending == nil {
+		panic("acceptOwnership: there is no proposed owner")
+	}
+
+	owner := pending.(interop.Hash160)
+	if !runtime.CheckWitness(owner) {
+		panic("acceptOwnership: only proposed owner can accept ownership")
+	}
+
+	previous := storage.Get(ctx, OwnerKey)
+
+	storage.Put(ctx, OwnerKey, owner)
+	storage.Delete(ctx, PendingOwnerKey)
+
+	runtime.Notify("OwnershipTransferred", previous, owner)
+}
+
+// CancelOwnerProposal removes owner candidate. Panics if there is no
+// candidate or invoker is not current contract owner.
+func CancelOwnerProposal(ctx storage.Context) {
+	pending := storage.Get(ctx, PendingOwnerKey)
+	if pending == nil {
+		panic("cancelOwnerProposal: there is no proposed owner")
+	}
+
+	current := storage.Get(ctx, OwnerKey).(interop.Hash160)
+	if !runtime.CheckWitness(current) {
+		panic("cancelOwnerProposal: only owner can cancel proposal")
+	}
+
+	storage.Delete(ctx, PendingOwnerKey)
+
+	runtime.Notify("OwnerProposalCancelled", pending)
+}
+
+// PendingOwner returns proposed owner candidate or nil if there is no
+// candidate.
+func PendingOwner(ctx storage.Context) interop.Hash160 {
+	data := storage.Get(ctx, PendingOwnerKey)
+	if data == nil {
+		return nil
+	}
+
+	return data.(interop.Hash160)
+}
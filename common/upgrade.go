@@ -0,0 +1,154 @@
+package common
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/interop/native/crypto"
+	"github.com/nspcc-dev/neo-go/pkg/interop/native/ledger"
+	"github.com/nspcc-dev/neo-go/pkg/interop/native/std"
+	"github.com/nspcc-dev/neo-go/pkg/interop/runtime"
+	"github.com/nspcc-dev/neo-go/pkg/interop/storage"
+)
+
+// UpgradeProposal describes contract update waiting for alphabet approval
+// or for the end of upgrade delay.
+type UpgradeProposal struct {
+	// Hash of script and manifest of the new contract, see UpgradeHash.
+	Hash []byte
+
+	// Height of block with alphabet approval, 0 if update is not approved.
+	ApprovedAt int
+}
+
+const (
+	UpgradeDelayKey    = "upgradeDelay"
+	UpgradeProposalKey = "upgradeProposal"
+)
+
+// UpgradeDelay returns amount of blocks between alphabet approval and
+// execution of contract update. Zero value means that upgrade governance
+// is disabled and contract owner can update contract instantly.
+func UpgradeDelay(ctx storage.Context) int {
+	data := storage.Get(ctx, UpgradeDelayKey)
+	if data == nil {
+		return 0
+	}
+
+	return data.(int)
+}
+
+// SetUpgradeDelay stores amount of blocks between alphabet approval and
+// execution of contract update. Zero delay disables upgrade governance.
+func SetUpgradeDelay(ctx storage.Context, delay int) {
+	if delay < 0 {
+		panic("setUpgradeDelay: negative delay")
+	}
+
+	storage.Put(ctx, UpgradeDelayKey, delay)
+
+	runtime.Notify("UpgradeDelaySet", delay)
+}
+
+// GetUpgradeProposal returns pending contract update. If there is no
+// pending update, then proposal with empty hash is returned.
+func GetUpgradeProposal(ctx storage.Context) UpgradeProposal {
+	data := storage.Get(ctx, UpgradeProposalKey)
+	if data != nil {
+		return std.Deserialize(data.([]byte)).(UpgradeProposal)
+	}
+
+	return UpgradeProposal{Hash: []byte{}}
+}
+
+// ProposeUpgrade stores new contract update waiting for alphabet approval.
+// Panics if upgrade governance is disabled or there is another pending
+// update.
+func ProposeUpgrade(ctx storage.Context, hash []byte) {
+	if UpgradeDelay(ctx) == 0 {
+		panic("proposeUpgrade: upgrade governance is disabled")
+	}
+
+	if len(hash) != 32 {
+		panic("proposeUpgrade: incorrect length of update hash")
+	}
+
+	if storage.Get(ctx, UpgradeProposalKey) != nil {
+		panic("proposeUpgrade: there is pending contract update")
+	}
+
+	SetSerialized(ctx, UpgradeProposalKey, UpgradeProposal{Hash: hash})
+
+	runtime.Notify("UpgradeProposed", hash)
+}
+
+// ApproveUpgrade marks pending contract update with provided hash as
+// approved by alphabet. Update can be executed after upgrade delay since
+// approval.
+func ApproveUpgrade(ctx storage.Context, hash []byte) {
+	proposal := GetUpgradeProposal(ctx)
+	if !BytesEqual(proposal.Hash, hash) {
+		panic("approveUpgrade: there is no pending update with provided hash")
+	}
+
+	if proposal.ApprovedAt != 0 {
+		panic("approveUpgrade: update has already been approved")
+	}
+
+	proposal.ApprovedAt = ledger.CurrentIndex()
+	SetSerialized(ctx, UpgradeProposalKey, proposal)
+
+	runtime.Notify("UpgradeApproved", hash, proposal.ApprovedAt+UpgradeDelay(ctx))
+}
+
+// CancelUpgrade removes pending contract update.
+func CancelUpgrade(ctx storage.Context) {
+	proposal := GetUpgradeProposal(ctx)
+	if len(proposal.Hash) == 0 {
+		panic("cancelUpgrade: there is no pending update")
+	}
+
+	storage.Delete(ctx, UpgradeProposalKey)
+
+	runtime.Notify("UpgradeCancelled", proposal.Hash)
+}
+
+// CheckUpgrade must be called before contract update. If upgrade governance
+// is enabled, it panics unless provided script and manifest match approved
+// pending update and upgrade delay has passed. Pending update is removed on
+// success.
+func CheckUpgrade(ctx storage.Context, script, manifest []byte) {
+	delay := UpgradeDelay(ctx)
+	if delay == 0 {
+		return
+	}
+
+	proposal := GetUpgradeProposal(ctx)
+	if proposal.ApprovedAt == 0 {
+		panic("update has not been approved by alphabet")
+	}
+
+	if !BytesEqual(proposal.Hash, UpgradeHash(script, manifest)) {
+		panic("update does not match approved proposal")
+	}
+
+	if ledger.CurrentIndex() < proposal.ApprovedAt+delay {
+		panic("upgrade delay has not passed yet")
+	}
+
+	storage.Delete(ctx, UpgradeProposalKey)
+}
+
+// UpgradeHash returns SHA256 hash of concatenated SHA256 hashes of script and
+// manifest. Hashing them separately makes the boundary between script and
+// manifest unambiguous.
+func UpgradeHash(script, manifest []byte) []byte {
+	return crypto.Sha256(append(crypto.Sha256(script), crypto.Sha256(manifest)...))
+}
+
+// CheckNotGoverned must be called by owner methods that change contract
+// state bypassing upgrade governance, e.g. contract reinitialization. It
+// panics if upgrade governance is enabled: such changes must be made by
+// approved contract update then.
+func CheckNotGoverned(ctx storage.Context, method string) {
+	if UpgradeDelay(ctx) != 0 {
+		panic(method + ": upgrade governance is enabled, use approved contract update")
+	}
+}
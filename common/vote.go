@@ -2,9 +2,11 @@ package common
 
 import (
 	"github.com/nspcc-dev/neo-go/pkg/interop"
+	"github.com/nspcc-dev/neo-go/pkg/interop/iterator"
 	"github.com/nspcc-dev/neo-go/pkg/interop/native/crypto"
 	"github.com/nspcc-dev/neo-go/pkg/interop/native/ledger"
 	"github.com/nspcc-dev/neo-go/pkg/interop/native/std"
+	"github.com/nspcc-dev/neo-go/pkg/interop/runtime"
 	"github.com/nspcc-dev/neo-go/pkg/interop/storage"
 	"github.com/nspcc-dev/neo-go/pkg/interop/util"
 )
@@ -18,90 +20,342 @@ type Ballot struct {
 
 	// Height of block with the last vote.
 	Height int
+
+	// Amount of blocks after the last vote when ballot expires.
+	Expiry int
 }
 
-const voteKey = "ballots"
+// BallotState describes pending ballot for inspection purposes.
+type BallotState struct {
+	// ID of the voting decision.
+	ID []byte
 
-const blockDiff = 20 // change base on performance evaluation
+	// Public keys of already voted inner ring nodes.
+	Voters []interop.PublicKey
 
-func InitVote(ctx storage.Context) {
-	SetSerialized(ctx, voteKey, []Ballot{})
+	// Height of block with the last vote.
+	Height int
+
+	// Amount of blocks left before ballot expiration.
+	BlocksLeft int
 }
 
-// Vote adds ballot for the decision with specific 'id' and returns amount
-// on unique voters for that decision.
-func Vote(ctx storage.Context, id, from []byte) int {
+// VotePolicy describes ballot expiry and quorum of the decisions made
+// without notary. Decision is accepted when amount of unique voters is bigger
+// than Numerator/Denominator part of alphabet nodes.
+type VotePolicy struct {
+	// Amount of blocks after the last vote when ballot expires.
+	Expiry int
+
+	// Numerator of the quorum fraction.
+	Numerator int
+
+	// Denominator of the quorum fraction.
+	Denominator int
+}
+
+const (
+	voteKey   = "ballots"
+	policyKey = "votePolicy"
+)
+
+const blockDiff = 20 // default ballot expiry, change base on performance evaluation
+
+// InitVote prepares contract storage for ballots. If contract storage still
+// contains ballot list of the previous layout, where all ballots were
+// serialized under single key, then active ballots are moved under their own
+// keys and expired ones are dropped.
+func InitVote(ctx storage.Context) {
+	data := storage.Get(ctx, voteKey)
+	if data == nil {
+		return
+	}
+
 	var (
-		newCandidates []Ballot
-		candidates    = getBallots(ctx)
-		found         = -1
-		blockHeight   = ledger.CurrentIndex()
+		candidates  = std.Deserialize(data.([]byte)).([]Ballot)
+		blockHeight = ledger.CurrentIndex()
 	)
 
 	for i := 0; i < len(candidates); i++ {
 		cnd := candidates[i]
-
 		if blockHeight-cnd.Height > blockDiff {
 			continue
 		}
 
-		if BytesEqual(cnd.ID, id) {
-			voters := cnd.Voters
+		SetSerialized(ctx, ballotKey(cnd.ID), Ballot{
+			ID:     cnd.ID,
+			Voters: cnd.Voters,
+			Height: cnd.Height,
+			Expiry: blockDiff,
+		})
+	}
+
+	storage.Delete(ctx, voteKey)
+}
 
-			for j := range voters {
-				if BytesEqual(voters[j], from) {
-					return len(voters)
-				}
-			}
+// Vote adds ballot for the decision with specific 'id' made by contract
+// 'method' and returns true if the decision has reached quorum of 'n'
+// alphabet nodes according to the vote policy of the method.
+func Vote(ctx storage.Context, method string, id, from []byte, n int) bool {
+	var (
+		key         = ballotKey(id)
+		policy      = GetVotePolicy(ctx, method)
+		threshold   = n*policy.Numerator/policy.Denominator + 1
+		blockHeight = ledger.CurrentIndex()
+		voters      []interop.PublicKey
+	)
 
-			voters = append(voters, from)
-			cnd = Ballot{ID: id, Voters: voters, Height: blockHeight}
-			found = len(voters)
+	data := storage.Get(ctx, key)
+	if data != nil {
+		cnd := std.Deserialize(data.([]byte)).(Ballot)
+
+		// expired ballot is replaced by the new one
+		if blockHeight-cnd.Height <= cnd.Expiry {
+			voters = cnd.Voters
+		}
+	}
+
+	for i := range voters {
+		if BytesEqual(voters[i], from) {
+			return len(voters) >= threshold
 		}
+	}
+
+	voters = append(voters, from)
+
+	SetSerialized(ctx, key, Ballot{
+		ID:     id,
+		Voters: voters,
+		Height: blockHeight,
+		Expiry: policy.Expiry,
+	})
+
+	return len(voters) >= threshold
+}
+
+// AlphabetDecision checks that contract 'method' is invoked by side chain
+// alphabet nodes and returns true if the decision with specific 'args' must
+// be executed. With notary, alphabet multisignature witness is required.
+// Without notary, vote of the invoker is collected and true is returned when
+// the decision has reached quorum. Panics if invoker is not an alphabet node.
+func AlphabetDecision(ctx storage.Context, notaryDisabled bool, method string, args []interface{}) bool {
+	if !notaryDisabled {
+		if !runtime.CheckWitness(AlphabetAddress()) {
+			panic(method + ": this method must be invoked by alphabet nodes")
+		}
+
+		return true
+	}
 
-		newCandidates = append(newCandidates, cnd)
+	return NodesDecision(ctx, AlphabetNodes(), method, args)
+}
+
+// NodesDecision collects vote of the invoker from 'nodes' for the decision
+// of contract 'method' with specific 'args' and returns true when the
+// decision has reached quorum. Panics if invoker is not one of 'nodes'.
+func NodesDecision(ctx storage.Context, nodes []IRNode, method string, args []interface{}) bool {
+	nodeKey := InnerRingInvoker(nodes)
+	if len(nodeKey) == 0 {
+		panic(method + ": this method must be invoked by alphabet nodes")
+	}
+
+	id := InvokeID(args, []byte(method))
+	if !Vote(ctx, method, id, nodeKey, len(nodes)) {
+		return false
 	}
 
-	if found < 0 {
-		voters := []interop.PublicKey{from}
-		newCandidates = append(newCandidates, Ballot{
-			ID:     id,
-			Voters: voters,
-			Height: blockHeight})
-		found = 1
+	RemoveVotes(ctx, id)
+
+	return true
+}
+
+// PurgeVotes removes all ballots from contract storage. Used when contract
+// starts to collect signatures with notary.
+func PurgeVotes(ctx storage.Context) {
+	var keys [][]byte
+
+	it := storage.Find(ctx, voteKey, storage.KeysOnly)
+	for iterator.Next(it) {
+		key := iterator.Value(it).([]byte) // it MUST BE `storage.KeysOnly`
+		keys = append(keys, key)
+	}
+
+	for i := range keys {
+		storage.Delete(ctx, keys[i])
+	}
+}
+
+// PurgeExpiredVotes removes at most 'limit' expired ballots from contract
+// storage and returns amount of removed ballots. Ballots of the decisions
+// that never reach quorum are replaced by the next vote for the same
+// decision only, so other ones must be removed by this method.
+func PurgeExpiredVotes(ctx storage.Context, limit int) int {
+	var (
+		keys        [][]byte
+		blockHeight = ledger.CurrentIndex()
+	)
+
+	it := storage.Find(ctx, voteKey, storage.None)
+	for len(keys) < limit && iterator.Next(it) {
+		pair := iterator.Value(it).([]interface{})
+		key := pair[0].([]byte)
+		if len(key) == len(voteKey) {
+			continue // ballot list of the previous storage layout
+		}
+
+		cnd := std.Deserialize(pair[1].([]byte)).(Ballot)
+		if blockHeight-cnd.Height > cnd.Expiry {
+			keys = append(keys, key)
+		}
 	}
 
-	SetSerialized(ctx, voteKey, newCandidates)
+	for i := range keys {
+		storage.Delete(ctx, keys[i])
+	}
 
-	return found
+	return len(keys)
 }
 
 // RemoveVotes clears ballots of the decision that has been accepted by
 // inner ring nodes.
 func RemoveVotes(ctx storage.Context, id []byte) {
+	storage.Delete(ctx, ballotKey(id))
+}
+
+// RevokeVote removes vote of 'from' node from the pending ballot of the
+// decision with specific 'id'. Returns false if there is no such vote.
+func RevokeVote(ctx storage.Context, id, from []byte) bool {
 	var (
-		newCandidates []Ballot
-		candidates    = getBallots(ctx)
+		key         = ballotKey(id)
+		blockHeight = ledger.CurrentIndex()
+		voters      []interop.PublicKey
+		found       bool
 	)
 
-	for i := 0; i < len(candidates); i++ {
-		cnd := candidates[i]
-		if !BytesEqual(cnd.ID, id) {
-			newCandidates = append(newCandidates, cnd)
+	data := storage.Get(ctx, key)
+	if data == nil {
+		return false
+	}
+
+	cnd := std.Deserialize(data.([]byte)).(Ballot)
+	if blockHeight-cnd.Height > cnd.Expiry {
+		storage.Delete(ctx, key)
+		return false
+	}
+
+	for i := range cnd.Voters {
+		if BytesEqual(cnd.Voters[i], from) {
+			found = true
+			continue
 		}
+
+		voters = append(voters, cnd.Voters[i])
+	}
+
+	if !found {
+		return false
+	}
+
+	if len(voters) == 0 {
+		storage.Delete(ctx, key)
+	} else {
+		cnd.Voters = voters
+		SetSerialized(ctx, key, cnd)
 	}
 
-	SetSerialized(ctx, voteKey, newCandidates)
+	return true
 }
 
-// getBallots returns deserialized slice of vote ballots.
-func getBallots(ctx storage.Context) []Ballot {
-	data := storage.Get(ctx, voteKey)
+// Ballots returns list of pending ballots that are not expired yet.
+func Ballots(ctx storage.Context) []BallotState {
+	var (
+		result      []BallotState
+		blockHeight = ledger.CurrentIndex()
+	)
+
+	it := storage.Find(ctx, voteKey, storage.None)
+	for iterator.Next(it) {
+		pair := iterator.Value(it).([]interface{})
+		key := pair[0].([]byte)
+		if len(key) == len(voteKey) {
+			continue // ballot list of the previous storage layout
+		}
+
+		cnd := std.Deserialize(pair[1].([]byte)).(Ballot)
+		if blockHeight-cnd.Height > cnd.Expiry {
+			continue
+		}
+
+		result = append(result, ballotState(cnd, blockHeight))
+	}
+
+	return result
+}
+
+// GetBallot returns pending ballot of the decision with specific 'id'. If
+// there is no such ballot or it has been expired, then ballot without voters
+// is returned. GetBallot is used by safe contract methods, so expired ballot
+// is not removed here, it is removed by the next Vote invocation.
+func GetBallot(ctx storage.Context, id []byte) BallotState {
+	blockHeight := ledger.CurrentIndex()
+
+	data := storage.Get(ctx, ballotKey(id))
+	if data != nil {
+		cnd := std.Deserialize(data.([]byte)).(Ballot)
+		if blockHeight-cnd.Height <= cnd.Expiry {
+			return ballotState(cnd, blockHeight)
+		}
+	}
+
+	return BallotState{ID: id, Voters: []interop.PublicKey{}}
+}
+
+func ballotState(b Ballot, blockHeight int) BallotState {
+	return BallotState{
+		ID:         b.ID,
+		Voters:     b.Voters,
+		Height:     b.Height,
+		BlocksLeft: b.Expiry - (blockHeight - b.Height),
+	}
+}
+
+// GetVotePolicy returns vote policy of the contract 'method'. If method has
+// no own policy, then contract-wide policy is returned. If there is no
+// contract-wide policy, then default 2/3n+1 quorum with 20 blocks
+// expiry is returned.
+func GetVotePolicy(ctx storage.Context, method string) VotePolicy {
+	data := storage.Get(ctx, policyKey+method)
+	if data == nil && len(method) != 0 {
+		data = storage.Get(ctx, policyKey)
+	}
+
 	if data != nil {
-		return std.Deserialize(data.([]byte)).([]Ballot)
+		return std.Deserialize(data.([]byte)).(VotePolicy)
 	}
 
-	return []Ballot{}
+	return VotePolicy{
+		Expiry:      blockDiff,
+		Numerator:   2,
+		Denominator: 3,
+	}
+}
+
+// SetVotePolicy stores vote policy of the contract 'method'. If method is
+// empty, then contract-wide policy is stored.
+func SetVotePolicy(ctx storage.Context, method string, p VotePolicy) {
+	SetSerialized(ctx, policyKey+method, p)
+}
+
+// ValidVotePolicy returns true if policy has positive expiry and quorum
+// fraction in [2/3, 1) range. Quorum can't be weaker than the one of alphabet
+// multisignature account, see Multiaddress.
+func ValidVotePolicy(p VotePolicy) bool {
+	return p.Expiry > 0 && p.Denominator > p.Numerator && 3*p.Numerator >= 2*p.Denominator
+}
+
+// ballotKey returns storage key of the ballot with specified id.
+func ballotKey(id []byte) []byte {
+	return append([]byte(voteKey), id...)
 }
 
 // BytesEqual compares two slice of bytes by wrapping them into strings,
@@ -138,10 +392,11 @@ func InvokeID(args []interface{}, prefix []byte) []byte {
    MaliciousIR  -(1 invoke)-> [ Malicious Contract ] -(1 invoke) -> [ Balance Contract ]
 
    To prevent that, we have to allow 1 invoke transfer from authorised well known
-   smart-contracts, that will be set up at `Init` method.
+   smart-contracts, that will be set up at `Init` method or resolved through
+   registry contract.
 */
 
-func FromKnownContract(ctx storage.Context, caller interop.Hash160, key string) bool {
-	addr := storage.Get(ctx, key).(interop.Hash160)
+func FromKnownContract(ctx storage.Context, caller interop.Hash160, name, key string) bool {
+	addr := ContractAddress(ctx, name, key)
 	return BytesEqual(caller, addr)
 }
@@ -18,9 +18,10 @@ type (
 	}
 
 	extendedACL struct {
-		val []byte
-		sig []byte
-		pub interop.PublicKey
+		val   []byte
+		sig   []byte
+		pub   interop.PublicKey
+		epoch int
 	}
 
 	estimation struct {
@@ -32,10 +33,44 @@ type (
 		cid         []byte
 		estimations []estimation
 	}
+
+	// containerSize is a size estimation of the container in batch
+	// estimation submission.
+	containerSize struct {
+		cid  []byte
+		size int
+	}
+
+	aggregatedSize struct {
+		epoch int
+		size  int
+	}
+
+	// paidFee is a container creation fee paid to every alphabet node
+	// at the moment of container creation.
+	paidFee struct {
+		amount int
+		nodes  []interop.Hash160
+	}
+
+	// containerPage is a page of container list, see ListPage.
+	containerPage struct {
+		ids [][]byte
+		// cursor of the next page, empty if there are no more containers.
+		cursor []byte
+	}
+
+	// indexMigration is a progress of container index building, see
+	// MigrateIndex.
+	indexMigration struct {
+		owners [][]byte
+		// next is an index of the next owner to process.
+		next int
+	}
 )
 
 const (
-	version   = 1
+	version   = 5
 	ownersKey = "ownersList"
 
 	neofsIDContractKey = "identityScriptHash"
@@ -44,17 +79,110 @@ const (
 	notaryDisabledKey  = "notary"
 
 	containerFeeKey = "ContainerFee"
-
-	containerIDSize = 32 // SHA256 size
+	// replicaFeeKey is a netmap config key with additional container fee
+	// for every object replica in container placement policy.
+	replicaFeeKey = "ContainerFeePerReplica"
+	// tierFeeKeyPrefix is a prefix of netmap config keys with container fee
+	// of the tier, that replaces default container fee.
+	tierFeeKeyPrefix = "ContainerFeeTier"
+	// deletedEpochsKey is a netmap config key with the amount of epochs
+	// during which ID of the deleted container can't be reused.
+	deletedEpochsKey = "ContainerDeletedEpochs"
+	// expirationGraceKey is a netmap config key with the amount of epochs
+	// after container expiration before it is removed.
+	expirationGraceKey = "ContainerExpirationGrace"
+	// deleteFeeKey is a netmap config key with the fee charged by every
+	// alphabet node for container deletion.
+	deleteFeeKey = "ContainerDeleteFee"
+	// refundPercentKey is a netmap config key with the percent of container
+	// creation fee refunded by every alphabet node on container deletion.
+	refundPercentKey = "ContainerRefundPercent"
+	// deletionGraceKey is a netmap config key with the amount of epochs
+	// during which deleted container structure is kept.
+	deletionGraceKey = "ContainerDeletionGrace"
+	// maxContainersKey is a netmap config key with default maximum amount
+	// of containers per owner.
+	maxContainersKey = "MaxContainersPerOwner"
+
+	// expirationAttribute is a container attribute with the last epoch of
+	// container lifetime.
+	expirationAttribute = "__NEOFS__EXPIRATION_EPOCH"
+	// tierAttribute is a container attribute with the name of container
+	// pricing tier.
+	tierAttribute = "__NEOFS__TIER"
+	// maxEpochDigits is a maximum length of the epoch number in container
+	// attributes.
+	maxEpochDigits = 18
 
 	estimateKeyPrefix = "cnr"
 	cleanupDelta      = 3
+
+	sizeKeyPrefix = "size"
+	// sizeHistoryDepth is an amount of epochs during which aggregated
+	// container size is kept.
+	sizeHistoryDepth = 100
+	// stoppedEstimationKey stores the latest epoch which estimation has been
+	// stopped.
+	stoppedEstimationKey = "stoppedEstimation"
+
+	deletedKeyPrefix  = "deleted"
+	ownerKeyPrefix    = "owner"
+	byOwnerKeyPrefix  = "byOwner"
+	nameKeyPrefix     = "name"
+	cidNameKeyPrefix  = "cidName"
+	expirationPrefix  = "expire"
+	expiryIndexPrefix = "expiry"
+	quotaKeyPrefix    = "quota"
+	feeKeyPrefix      = "fee"
+
+	// releaseCnrPrefix and releaseMarkPrefix schedule removal of the deleted
+	// container structure and deletion mark by the release epoch.
+	releaseCnrPrefix  = "releaseCnr"
+	releaseMarkPrefix = "releaseMark"
+
+	indexMigrationKey = "indexMigration"
+	// lastEpochKey stores the epoch of the last processed NewEpoch call.
+	lastEpochKey = "lastEpoch"
+	// lastRemovedExpirationKey stores the latest expiration epoch up to which
+	// expired containers have been removed.
+	lastRemovedExpirationKey = "lastRemovedExpiration"
+
+	defaultZone   = "container"
+	maxNameLength = 63
+
+	eACLHistoryPrefix = "aclHistory"
+	eACLCleanupPrefix = "aclCleanup"
+	eACLHashPrefix    = "aclHash"
+	usedMessagePrefix = "usedMsg"
+	// eACLHistoryDepth is an amount of epochs during which replaced eACL
+	// is kept in the history.
+	eACLHistoryDepth = 100
+	// eACLHistorySize is a maximum amount of eACLs in container history.
+	eACLHistorySize = 16
 )
 
 var (
 	eACLPrefix = []byte("eACL")
 )
 
+// Pausable methods of the contract, see Pause.
+var (
+	// userMethods are stopped by the pause of the whole contract.
+	userMethods = []string{"put", "delete", "transferContainer", "setEACL", "deleteEACL", "putContainerSize"}
+	// systemMethods are invoked by inner ring nodes, so they are stopped
+	// only by their own pause.
+	systemMethods = []string{"setOwnerQuota", "startContainerEstimation", "stopContainerEstimation"}
+)
+
+func _deploy(data interface{}, isUpdate bool) {
+	ctx := storage.GetContext()
+
+	prev := common.CommitVersion(ctx, version)
+	if isUpdate {
+		migrateStorage(ctx, prev)
+	}
+}
+
 func Init(notaryDisabled bool, owner, addrNetmap, addrBalance, addrID interop.Hash160) {
 	ctx := storage.GetContext()
 
@@ -62,6 +190,8 @@ func Init(notaryDisabled bool, owner, addrNetmap, addrBalance, addrID interop.Ha
 		panic("only owner can reinitialize contract")
 	}
 
+	common.CheckNotGoverned(ctx, "init")
+
 	if len(addrNetmap) != 20 || len(addrBalance) != 20 || len(addrID) != 20 {
 		panic("init: incorrect length of contract script hash")
 	}
@@ -82,28 +212,320 @@ func Init(notaryDisabled bool, owner, addrNetmap, addrBalance, addrID interop.Ha
 }
 
 func Migrate(script []byte, manifest []byte) bool {
-	ctx := storage.GetReadOnlyContext()
+	ctx := storage.GetContext()
 
 	if !common.HasUpdateAccess(ctx) {
 		runtime.Log("only owner can update contract")
 		return false
 	}
 
+	common.CheckUpgrade(ctx, script, manifest)
 	management.Update(script, manifest)
 	runtime.Log("container contract updated")
 
 	return true
 }
 
+// ProposeOwner proposes new contract owner. Ownership is transferred after
+// proposed owner accepts it.
+func ProposeOwner(owner interop.Hash160) bool {
+	ctx := storage.GetContext()
+	common.ProposeOwner(ctx, owner)
+
+	return true
+}
+
+// AcceptOwnership transfers contract ownership to the proposed owner.
+func AcceptOwnership() bool {
+	ctx := storage.GetContext()
+	common.AcceptOwnership(ctx)
+
+	runtime.Log("container contract owner changed")
+
+	return true
+}
+
+// CancelOwnerProposal removes proposed contract owner.
+func CancelOwnerProposal() bool {
+	ctx := storage.GetContext()
+	common.CancelOwnerProposal(ctx)
+
+	return true
+}
+
+// PendingOwner returns proposed contract owner if there is one.
+func PendingOwner() interop.Hash160 {
+	ctx := storage.GetReadOnlyContext()
+	return common.PendingOwner(ctx)
+}
+
+// Migrations returns history of contract deploy and updates.
+func Migrations() []common.Migration {
+	ctx := storage.GetReadOnlyContext()
+	return common.Migrations(ctx)
+}
+
+// MigrateIndex builds container indices missing after update from the
+// contract version older than 4. Owners are processed one by one until
+// indices of at least 'limit' containers are built, so the method must be
+// invoked until it returns true. Can be invoked by the contract owner.
+func MigrateIndex(limit int) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		panic("migrateIndex: only owner can migrate contract storage")
+	}
+
+	if limit <= 0 {
+		panic("migrateIndex: invalid limit")
+	}
+
+	data := storage.Get(ctx, indexMigrationKey)
+	if data == nil {
+		return true
+	}
+
+	m := std.Deserialize(data.([]byte)).(indexMigration)
+
+	for count := 0; m.next < len(m.owners) && count < limit; m.next++ {
+		owner := m.owners[m.next]
+
+		containers := common.GetList(ctx, owner)
+		for i := range containers {
+			storage.Put(ctx, ownerKey(containers[i]), owner)
+			storage.Put(ctx, byOwnerKey(owner, containers[i]), containers[i])
+		}
+
+		count += len(containers)
+	}
+
+	if m.next < len(m.owners) {
+		common.SetSerialized(ctx, indexMigrationKey, m)
+		return false
+	}
+
+	storage.Delete(ctx, indexMigrationKey)
+	runtime.Log("migrateIndex: container indices have been built")
+
+	return true
+}
+
+// SetRegistry sets registry contract used to resolve addresses of other
+// NeoFS contracts. Addresses provided in Init are used if registry contract
+// can't resolve them.
+func SetRegistry(addr interop.Hash160) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		panic("setRegistry: only owner can set registry contract")
+	}
+
+	common.CheckNotGoverned(ctx, "setRegistry")
+
+	common.SetRegistry(ctx, addr)
+	runtime.Log("setRegistry: registry contract has been set")
+
+	return true
+}
+
+// SetUpgradeDelay sets amount of blocks between alphabet approval and
+// execution of contract update. Non-zero delay enables upgrade governance
+// and can be set by contract owner, after that delay can be changed by
+// alphabet nodes only.
+func SetUpgradeDelay(delay int) bool {
+	ctx := storage.GetContext()
+
+	if common.UpgradeDelay(ctx) == 0 {
+		if !common.HasUpdateAccess(ctx) {
+			panic("setUpgradeDelay: only owner can enable upgrade governance")
+		}
+	} else {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "setUpgradeDelay", []interface{}{delay}) {
+			return true
+		}
+	}
+
+	common.SetUpgradeDelay(ctx, delay)
+	runtime.Log("setUpgradeDelay: upgrade delay has been updated")
+
+	return true
+}
+
+// ProposeUpgrade proposes contract update with provided SHA256 hash of
+// concatenated SHA256 hashes of script and manifest. Available if upgrade
+// governance is enabled.
+func ProposeUpgrade(hash []byte) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		panic("proposeUpgrade: only owner can propose contract update")
+	}
+
+	common.ProposeUpgrade(ctx, hash)
+	runtime.Log("proposeUpgrade: contract update has been proposed")
+
+	return true
+}
+
+// ApproveUpgrade approves pending contract update with provided hash.
+// Approved update can be executed after upgrade delay.
+func ApproveUpgrade(hash []byte) bool {
+	ctx := storage.GetContext()
+	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+	if !common.AlphabetDecision(ctx, notaryDisabled, "approveUpgrade", []interface{}{hash}) {
+		return true
+	}
+
+	common.ApproveUpgrade(ctx, hash)
+	runtime.Log("approveUpgrade: contract update has been approved")
+
+	return true
+}
+
+// CancelUpgrade removes pending contract update. Can be invoked by the
+// contract owner or alphabet nodes.
+func CancelUpgrade() bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+		proposal := common.GetUpgradeProposal(ctx)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "cancelUpgrade", []interface{}{proposal.Hash}) {
+			return true
+		}
+	}
+
+	common.CancelUpgrade(ctx)
+	runtime.Log("cancelUpgrade: contract update has been cancelled")
+
+	return true
+}
+
+// UpgradeProposal returns pending contract update.
+func UpgradeProposal() common.UpgradeProposal {
+	ctx := storage.GetReadOnlyContext()
+	return common.GetUpgradeProposal(ctx)
+}
+
+// UpgradeDelay returns amount of blocks between alphabet approval and
+// execution of contract update. Zero value means that upgrade governance
+// is disabled.
+func UpgradeDelay() int {
+	ctx := storage.GetReadOnlyContext()
+	return common.UpgradeDelay(ctx)
+}
+
+// Pause stops execution of the state-changing contract method. If method is
+// empty, then all user-facing methods are stopped, see userMethods. Can be
+// invoked by the contract owner or alphabet nodes.
+func Pause(method string) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "pause", []interface{}{method}) {
+			return true
+		}
+	}
+
+	common.Pause(ctx, method, userMethods, systemMethods)
+	runtime.Log("pause: contract method has been paused")
+
+	return true
+}
+
+// Unpause resumes execution of the contract method stopped by Pause. Can be
+// invoked by the contract owner or alphabet nodes.
+func Unpause(method string) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "unpause", []interface{}{method}) {
+			return true
+		}
+	}
+
+	common.Unpause(ctx, method)
+	runtime.Log("unpause: contract method has been unpaused")
+
+	return true
+}
+
+// IsPaused returns true if contract method is paused. Pause of the whole
+// contract affects only user-facing methods.
+func IsPaused(method string) bool {
+	ctx := storage.GetReadOnlyContext()
+	return common.IsPaused(ctx, method, userMethods)
+}
+
 func Put(container []byte, signature interop.Signature, publicKey interop.PublicKey) bool {
+	return put(container, signature, publicKey, "", "", nil, nil)
+}
+
+// PutWithSession creates container on behalf of the owner. Container must be
+// signed by the key delegated in the NeoFS API container session token with
+// PUT verb, token body must be signed by the owner with ECDSA_RFC6979_SHA256
+// scheme.
+func PutWithSession(container []byte, signature interop.Signature, publicKey interop.PublicKey, token, tokenSignature []byte) bool {
+	return put(container, signature, publicKey, "", "", token, tokenSignature)
+}
+
+// PutNamed creates container and registers its name in the zone. Name is
+// owned by the container owner and released on container removal. Empty
+// zone means default "container" zone.
+func PutNamed(container []byte, signature interop.Signature, publicKey interop.PublicKey, name, zone string) bool {
+	if len(zone) == 0 {
+		zone = defaultZone
+	}
+
+	return put(container, signature, publicKey, name, zone, nil, nil)
+}
+
+func put(container []byte, signature interop.Signature, publicKey interop.PublicKey,
+	name, zone string, token, tokenSignature []byte) bool {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "put", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 
-	offset := int(container[1])
-	offset = 2 + offset + 4                  // version prefix + version size + owner prefix
-	ownerID := container[offset : offset+25] // offset + size of owner
+	ownerID := common.ContainerOwnerID(container)
 	containerID := crypto.Sha256(container)
-	neofsIDContractAddr := storage.Get(ctx, neofsIDContractKey).(interop.Hash160)
+	neofsIDContractAddr := common.ContractAddress(ctx, common.NeoFSIDContractName, neofsIDContractKey)
+
+	// reject container before any assets are transferred, so inner ring
+	// can skip it by the notification
+	reason := checkUniqueID(ctx, containerID)
+	if len(reason) == 0 && len(name) != 0 {
+		reason = checkName(ctx, name, zone)
+	}
+
+	if len(reason) == 0 && isQuotaExceeded(ctx, ownerID) {
+		reason = "owner container quota exceeded"
+	}
+
+	expiration := 0
+	if len(reason) == 0 {
+		expiration, reason = checkExpiration(ctx, container)
+	}
+
+	containerFee := 0
+	if len(reason) == 0 {
+		containerFee = getContainerFee(ctx, container)
+		if containerFee < 0 {
+			reason = "unknown container tier"
+		}
+	}
+
+	if len(reason) != 0 {
+		runtime.Notify("containerPutFailed", containerID, reason)
+		return false
+	}
 
 	var ( // for invocation collection without notary
 		alphabet     = common.AlphabetNodes()
@@ -120,6 +542,17 @@ func Put(container []byte, signature interop.Signature, publicKey interop.Public
 	}
 
 	if !alphabetCall {
+		if len(token) != 0 {
+			key := checkSession(ctx, token, tokenSignature, "put", ownerID, nil)
+			if !crypto.VerifyWithECDsa(container, key, signature, crypto.Secp256r1) {
+				panic("put: invalid session signature")
+			}
+
+			runtime.Notify("containerPutSession", container, signature, publicKey, token, tokenSignature)
+
+			return true
+		}
+
 		if !isSignedByOwnerKey(container, signature, ownerID, publicKey) {
 			// check keys from NeoFSID
 			keys := contract.Call(neofsIDContractAddr, "key", contract.ReadOnly, ownerID).([]interop.PublicKey)
@@ -128,34 +561,44 @@ func Put(container []byte, signature interop.Signature, publicKey interop.Public
 			}
 		}
 
-		runtime.Notify("containerPut", container, signature, publicKey)
+		if len(name) != 0 {
+			runtime.Notify("containerPutNamed", container, signature, publicKey, name, zone)
+		} else {
+			runtime.Notify("containerPut", container, signature, publicKey)
+		}
 
 		return true
 	}
 
 	from := common.WalletToScriptHash(ownerID)
-	netmapContractAddr := storage.Get(ctx, netmapContractKey).(interop.Hash160)
-	balanceContractAddr := storage.Get(ctx, balanceContractKey).(interop.Hash160)
-	containerFee := contract.Call(netmapContractAddr, "config", contract.ReadOnly, containerFeeKey).(int)
+	balanceContractAddr := common.ContractAddress(ctx, common.BalanceContractName, balanceContractKey)
 	details := common.ContainerFeeTransferDetails(containerID)
 
-	// todo: check if new container with unique container id
-
 	if notaryDisabled {
-		threshold := len(alphabet)*2/3 + 1
-		id := common.InvokeID([]interface{}{container, signature, publicKey}, []byte("put"))
+		args := []interface{}{container, signature, publicKey}
+		if len(name) != 0 {
+			args = append(args, name, zone)
+		}
+
+		if len(token) != 0 {
+			args = append(args, token, tokenSignature)
+		}
+
+		id := common.InvokeID(args, []byte("put"))
 
-		n := common.Vote(ctx, id, nodeKey)
-		if n < threshold {
+		if !common.Vote(ctx, "put", id, nodeKey, len(alphabet)) {
 			return true
 		}
 
 		common.RemoveVotes(ctx, id)
 	}
 
+	feeNodes := []interop.Hash160{}
+
 	for i := 0; i < len(alphabet); i++ {
 		node := alphabet[i]
 		to := contract.CreateStandardAccount(node.PublicKey)
+		feeNodes = append(feeNodes, to)
 
 		tx := contract.Call(balanceContractAddr, "transferX",
 			contract.All,
@@ -170,7 +613,21 @@ func Put(container []byte, signature interop.Signature, publicKey interop.Public
 	}
 
 	addContainer(ctx, containerID, ownerID, container)
-	contract.Call(neofsIDContractAddr, "addKey", contract.All, ownerID, [][]byte{publicKey})
+	common.SetSerialized(ctx, feeKey(containerID), paidFee{amount: containerFee, nodes: feeNodes})
+
+	if len(name) != 0 {
+		addName(ctx, containerID, name, zone)
+	}
+
+	if expiration != 0 {
+		storage.Put(ctx, expirationKey(containerID), expiration)
+		storage.Put(ctx, expiryIndexKey(expiration, containerID), true)
+	}
+
+	// delegated key must not be bound to the owner
+	if len(token) == 0 {
+		contract.Call(neofsIDContractAddr, "addKey", contract.All, ownerID, [][]byte{publicKey})
+	}
 
 	runtime.Log("put: added new container")
 
@@ -178,7 +635,20 @@ func Put(container []byte, signature interop.Signature, publicKey interop.Public
 }
 
 func Delete(containerID, signature []byte) bool {
+	return deleteContainer(containerID, signature, nil, nil)
+}
+
+// DeleteWithSession removes container on behalf of the owner. Container ID
+// must be signed by the key delegated in the NeoFS API container session
+// token with DELETE verb, token body must be signed by the owner with
+// ECDSA_RFC6979_SHA256 scheme.
+func DeleteWithSession(containerID, signature, token, tokenSignature []byte) bool {
+	return deleteContainer(containerID, signature, token, tokenSignature)
+}
+
+func deleteContainer(containerID, signature, token, tokenSignature []byte) bool {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "delete", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 
 	ownerID := getOwnerByID(ctx, containerID)
@@ -202,8 +672,18 @@ func Delete(containerID, signature []byte) bool {
 	}
 
 	if !alphabetCall {
+		if len(token) != 0 {
+			key := checkSession(ctx, token, tokenSignature, "delete", ownerID, containerID)
+			if !crypto.VerifyWithECDsa(containerID, key, signature, crypto.Secp256r1) {
+				panic("delete: invalid session signature")
+			}
+
+			runtime.Notify("containerDeleteSession", containerID, signature, token, tokenSignature)
+			return true
+		}
+
 		// check provided key
-		neofsIDContractAddr := storage.Get(ctx, neofsIDContractKey).(interop.Hash160)
+		neofsIDContractAddr := common.ContractAddress(ctx, common.NeoFSIDContractName, neofsIDContractKey)
 		keys := contract.Call(neofsIDContractAddr, "key", contract.ReadOnly, ownerID).([]interop.PublicKey)
 
 		if !verifySignature(containerID, signature, keys) {
@@ -215,93 +695,393 @@ func Delete(containerID, signature []byte) bool {
 	}
 
 	if notaryDisabled {
-		threshold := len(alphabet)*2/3 + 1
-		id := common.InvokeID([]interface{}{containerID, signature}, []byte("delete"))
+		args := []interface{}{containerID, signature}
+		if len(token) != 0 {
+			args = append(args, token, tokenSignature)
+		}
+
+		id := common.InvokeID(args, []byte("delete"))
 
-		n := common.Vote(ctx, id, nodeKey)
-		if n < threshold {
+		if !common.Vote(ctx, "delete", id, nodeKey, len(alphabet)) {
 			return true
 		}
 
 		common.RemoveVotes(ctx, id)
 	}
 
+	if !notaryDisabled {
+		alphabet = common.AlphabetNodes()
+	}
+
+	chargeDeletion(ctx, alphabet, containerID, ownerID)
 	removeContainer(ctx, containerID, ownerID)
 	runtime.Log("delete: remove container")
 
 	return true
 }
 
-func Get(containerID []byte) []byte {
-	ctx := storage.GetReadOnlyContext()
-	return storage.Get(ctx, containerID).([]byte)
-}
-
-func Owner(containerID []byte) []byte {
-	ctx := storage.GetReadOnlyContext()
-	return getOwnerByID(ctx, containerID)
-}
-
-func List(owner []byte) [][]byte {
-	ctx := storage.GetReadOnlyContext()
-
-	if len(owner) == 0 {
-		return getAllContainers(ctx)
-	}
-
-	var list [][]byte
-
-	owners := common.GetList(ctx, ownersKey)
-	for i := 0; i < len(owners); i++ {
-		ownerID := owners[i]
-		if len(owner) != 0 && !common.BytesEqual(owner, ownerID) {
-			continue
-		}
-
-		containers := common.GetList(ctx, ownerID)
-
-		for j := 0; j < len(containers); j++ {
-			container := containers[j]
-			list = append(list, container)
-		}
-	}
-
-	return list
-}
-
-func SetEACL(eACL, signature []byte) bool {
+// TransferContainer moves container to the new owner. Signature of container
+// ID, current owner ID, new owner ID and current epoch number concatenation
+// must be made by the current owner key registered in NeoFS ID contract.
+// Signed transfer can be applied only once. Container structure keeps the
+// original owner ID, the actual owner is returned by Owner method. eACL of the
+// previous owner is removed, so the new owner has to set its own eACL.
+func TransferContainer(containerID, newOwner []byte, epoch int, signature []byte) bool {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "transferContainer", userMethods)
+	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 
-	// get container ID
-	offset := int(eACL[1])
-	offset = 2 + offset + 4
-	containerID := eACL[offset : offset+32]
+	if len(newOwner) != 25 {
+		panic("transferContainer: incorrect owner")
+	}
 
 	ownerID := getOwnerByID(ctx, containerID)
 	if len(ownerID) == 0 {
-		panic("setEACL: container does not exists")
+		panic("transferContainer: container does not exist")
 	}
 
-	neofsIDContractAddr := storage.Get(ctx, neofsIDContractKey).(interop.Hash160)
-	keys := contract.Call(neofsIDContractAddr, "key", contract.ReadOnly, ownerID).([]interop.PublicKey)
+	if common.BytesEqual(ownerID, newOwner) {
+		panic("transferContainer: container is already owned by this owner")
+	}
 
-	if !verifySignature(eACL, signature, keys) {
-		panic("setEACL: invalid eACL signature")
+	if isQuotaExceeded(ctx, newOwner) {
+		panic("transferContainer: owner container quota exceeded")
 	}
 
-	rule := extendedACL{
-		val: eACL,
-		sig: signature,
+	if epoch != currentEpoch(ctx) {
+		panic("transferContainer: invalid epoch")
 	}
 
-	key := append(eACLPrefix, containerID...)
-	common.SetSerialized(ctx, key, rule)
+	var buf interface{} = epoch
+
+	msg := append(containerID, ownerID...)
+	msg = append(msg, newOwner...)
+	msg = append(msg, buf.([]byte)...)
+
+	// protect from replay of previously made transfers
+	if isUsedMessage(ctx, epoch, msg) {
+		panic("transferContainer: transfer has already been made")
+	}
+
+	var ( // for invocation collection without notary
+		alphabet     []common.IRNode
+		nodeKey      []byte
+		alphabetCall bool
+	)
+
+	if notaryDisabled {
+		alphabet = common.AlphabetNodes()
+		nodeKey = common.InnerRingInvoker(alphabet)
+		alphabetCall = len(nodeKey) != 0
+	} else {
+		multiaddr := common.AlphabetAddress()
+		alphabetCall = runtime.CheckWitness(multiaddr)
+	}
+
+	if !alphabetCall {
+		// check provided key
+		neofsIDContractAddr := common.ContractAddress(ctx, common.NeoFSIDContractName, neofsIDContractKey)
+		keys := contract.Call(neofsIDContractAddr, "key", contract.ReadOnly, ownerID).([]interop.PublicKey)
+
+		if !verifySignature(msg, signature, keys) {
+			panic("transferContainer: invalid owner signature")
+		}
+
+		runtime.Notify("containerTransfer", containerID, newOwner, epoch, signature)
+		return true
+	}
+
+	if notaryDisabled {
+		id := common.InvokeID([]interface{}{containerID, newOwner, epoch, signature}, []byte("transferContainer"))
+
+		if !common.Vote(ctx, "transferContainer", id, nodeKey, len(alphabet)) {
+			return true
+		}
+
+		common.RemoveVotes(ctx, id)
+	}
+
+	if remove(ctx, ownerID, containerID) == 0 {
+		_ = remove(ctx, ownersKey, ownerID)
+	}
+
+	markUsedMessage(ctx, epoch, msg)
+
+	addOrAppend(ctx, ownersKey, newOwner)
+	addOrAppend(ctx, newOwner, containerID)
+	storage.Put(ctx, ownerKey(containerID), newOwner)
+	storage.Delete(ctx, byOwnerKey(ownerID, containerID))
+	storage.Put(ctx, byOwnerKey(newOwner, containerID), containerID)
+
+	dropEACL(ctx, containerID, ownerID, epoch)
+
+	runtime.Notify("ContainerOwnerChanged", containerID, ownerID, newOwner)
+
+	return true
+}
+
+// QuoteFee returns total amount of assets that owner pays to alphabet nodes
+// for the container creation or -1 if container tier is unknown.
+func QuoteFee(container []byte) int {
+	ctx := storage.GetReadOnlyContext()
+
+	fee := getContainerFee(ctx, container)
+	if fee < 0 {
+		return -1
+	}
+
+	return fee * len(common.AlphabetNodes())
+}
+
+// IsDeleted returns true if container was recently deleted. Structure of the
+// deleted container is still returned by Get during deletion grace period.
+func IsDeleted(containerID []byte) bool {
+	ctx := storage.GetReadOnlyContext()
+	return storage.Get(ctx, deletedKey(containerID)) != nil
+}
+
+func Get(containerID []byte) []byte {
+	ctx := storage.GetReadOnlyContext()
+	return storage.Get(ctx, containerID).([]byte)
+}
+
+func Owner(containerID []byte) []byte {
+	ctx := storage.GetReadOnlyContext()
+	return getOwnerByID(ctx, containerID)
+}
+
+func List(owner []byte) [][]byte {
+	ctx := storage.GetReadOnlyContext()
+
+	if len(owner) == 0 {
+		return getAllContainers(ctx)
+	}
+
+	var list [][]byte
+
+	owners := common.GetList(ctx, ownersKey)
+	for i := 0; i < len(owners); i++ {
+		ownerID := owners[i]
+		if len(owner) != 0 && !common.BytesEqual(owner, ownerID) {
+			continue
+		}
+
+		containers := common.GetList(ctx, ownerID)
+
+		for j := 0; j < len(containers); j++ {
+			container := containers[j]
+			list = append(list, container)
+		}
+	}
+
+	return list
+}
+
+// ListPage returns at most limit container IDs of the owner (or of all owners
+// if owner is empty) that follow the cursor, and the cursor of the next page.
+// Empty cursor starts listing from the beginning, empty cursor of the next
+// page means that there are no more containers. Cursor is a position in the
+// container index, so containers removed between calls don't break listing.
+func ListPage(owner, cursor []byte, limit int) containerPage {
+	ctx := storage.GetReadOnlyContext()
+
+	if len(owner) != 0 && len(owner) != 25 {
+		panic("listPage: invalid owner ID")
+	}
+
+	if limit <= 0 {
+		panic("listPage: invalid limit")
+	}
+
+	checkIndexMigrated(ctx, "listPage")
+
+	var (
+		prefix = append([]byte(byOwnerKeyPrefix), owner...)
+		keys   = listAfter(ctx, prefix, cursor, limit)
+		page   = containerPage{ids: [][]byte{}, cursor: []byte{}}
+	)
+
+	for i := range keys {
+		key := keys[i]
+		page.ids = append(page.ids, key[len(key)-32:])
+	}
+
+	if len(keys) == limit {
+		last := keys[len(keys)-1]
+		page.cursor = last[len(prefix):]
+	}
+
+	return page
+}
+
+// ListIterator returns iterator over container IDs of the owner (or of all
+// owners if owner is empty).
+func ListIterator(owner []byte) iterator.Iterator {
+	ctx := storage.GetReadOnlyContext()
+
+	if len(owner) != 0 && len(owner) != 25 {
+		panic("listIterator: invalid owner ID")
+	}
+
+	checkIndexMigrated(ctx, "listIterator")
+
+	return storage.Find(ctx, append([]byte(byOwnerKeyPrefix), owner...), storage.ValuesOnly)
+}
+
+// Resolve returns ID of the container registered with the name in the zone.
+// Empty zone means default "container" zone.
+func Resolve(name, zone string) []byte {
+	ctx := storage.GetReadOnlyContext()
+
+	if len(zone) == 0 {
+		zone = defaultZone
+	}
+
+	id := storage.Get(ctx, nameKey(name, zone))
+	if id == nil {
+		return nil
+	}
+
+	return id.([]byte)
+}
+
+// ListNames returns names of the owner's containers in "name.zone" format.
+func ListNames(owner []byte) []string {
+	ctx := storage.GetReadOnlyContext()
+
+	if len(owner) != 0 && len(owner) != 25 {
+		panic("listNames: invalid owner ID")
+	}
+
+	checkIndexMigrated(ctx, "listNames")
+
+	var names []string
+
+	it := storage.Find(ctx, append([]byte(byOwnerKeyPrefix), owner...), storage.ValuesOnly)
+	for iterator.Next(it) {
+		id := iterator.Value(it).([]byte)
+
+		name := storage.Get(ctx, append([]byte(cidNameKeyPrefix), id...))
+		if name != nil {
+			names = append(names, name.(string))
+		}
+	}
+
+	return names
+}
+
+// SetEACL sets eACL of the container. eACL must be signed by the owner key
+// registered in NeoFS ID contract. The same eACL can be set only once to
+// protect from replay of replaced eACLs.
+func SetEACL(eACL, signature []byte) bool {
+	return setEACL(eACL, signature, nil, nil)
+}
+
+// SetEACLWithSession sets eACL on behalf of the container owner. eACL must be
+// signed by the key delegated in the NeoFS API container session token with
+// SETEACL verb, token body must be signed by the owner with
+// ECDSA_RFC6979_SHA256 scheme.
+func SetEACLWithSession(eACL, signature, token, tokenSignature []byte) bool {
+	return setEACL(eACL, signature, token, tokenSignature)
+}
+
+func setEACL(eACL, signature, token, tokenSignature []byte) bool {
+	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "setEACL", userMethods)
+
+	containerID := common.EACLContainerID(eACL)
+
+	ownerID := getOwnerByID(ctx, containerID)
+	if len(ownerID) == 0 {
+		panic("setEACL: container does not exists")
+	}
+
+	var sessionKey interop.PublicKey
+
+	if len(token) != 0 {
+		sessionKey = checkSession(ctx, token, tokenSignature, "setEACL", ownerID, containerID)
+		if !crypto.VerifyWithECDsa(eACL, sessionKey, signature, crypto.Secp256r1) {
+			panic("setEACL: invalid session signature")
+		}
+	} else {
+		neofsIDContractAddr := common.ContractAddress(ctx, common.NeoFSIDContractName, neofsIDContractKey)
+		keys := contract.Call(neofsIDContractAddr, "key", contract.ReadOnly, ownerID).([]interop.PublicKey)
+
+		if !verifySignature(eACL, signature, keys) {
+			panic("setEACL: invalid eACL signature")
+		}
+	}
+
+	// protect from replay of previously set eACLs
+	if isUsedEACL(ctx, containerID, eACL) {
+		panic("setEACL: eACL has already been used")
+	}
+
+	storage.Put(ctx, eACLHashKey(containerID), crypto.Sha256(eACL))
+
+	// delegated key can't be found in NeoFS ID, so it is stored with eACL
+	rule := extendedACL{
+		val:   eACL,
+		sig:   signature,
+		pub:   sessionKey,
+		epoch: currentEpoch(ctx),
+	}
+
+	key := append(eACLPrefix, containerID...)
+	common.SetSerialized(ctx, key, rule)
+	addEACLHistory(ctx, containerID, rule)
 
 	runtime.Log("setEACL: success")
 
 	return true
 }
 
+// DeleteEACL removes eACL of the container. Signature of container ID and
+// current epoch number concatenation must be made by the owner key registered
+// in NeoFS ID contract.
+func DeleteEACL(containerID []byte, epoch int, signature []byte) bool {
+	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "deleteEACL", userMethods)
+
+	ownerID := getOwnerByID(ctx, containerID)
+	if len(ownerID) == 0 {
+		panic("deleteEACL: container does not exists")
+	}
+
+	current := currentEpoch(ctx)
+	if epoch != current {
+		panic("deleteEACL: invalid epoch")
+	}
+
+	neofsIDContractAddr := common.ContractAddress(ctx, common.NeoFSIDContractName, neofsIDContractKey)
+	keys := contract.Call(neofsIDContractAddr, "key", contract.ReadOnly, ownerID).([]interop.PublicKey)
+
+	var buf interface{} = epoch
+
+	msg := append(containerID, buf.([]byte)...)
+	if !verifySignature(msg, signature, keys) {
+		panic("deleteEACL: invalid signature")
+	}
+
+	if isUsedMessage(ctx, epoch, msg) {
+		panic("deleteEACL: eACL has already been deleted")
+	}
+
+	markUsedMessage(ctx, epoch, msg)
+
+	storage.Delete(ctx, append(eACLPrefix, containerID...))
+	addEACLHistory(ctx, containerID, extendedACL{
+		val:   []byte{},
+		sig:   interop.Signature{},
+		pub:   interop.PublicKey{},
+		epoch: current,
+	})
+
+	runtime.Log("deleteEACL: success")
+
+	return true
+}
+
 func EACL(containerID []byte) extendedACL {
 	ctx := storage.GetReadOnlyContext()
 
@@ -312,13 +1092,42 @@ func EACL(containerID []byte) extendedACL {
 
 	eacl := getEACL(ctx, containerID)
 
+	return attachEACLKey(ctx, ownerID, eacl)
+}
+
+// EACLAt returns eACL of the container that was effective at the specified
+// epoch. Returns empty eACL if there were no eACL set at that epoch or if it
+// is out of the history.
+func EACLAt(containerID []byte, epoch int) extendedACL {
+	ctx := storage.GetReadOnlyContext()
+
+	ownerID := getOwnerByID(ctx, containerID)
+	if len(ownerID) == 0 {
+		panic("eACLAt: container does not exists")
+	}
+
+	eacl := extendedACL{val: []byte{}, sig: interop.Signature{}, pub: interop.PublicKey{}}
+
+	history := getEACLHistory(ctx, containerID)
+	for i := 0; i < len(history); i++ {
+		if history[i].epoch > epoch {
+			break
+		}
+
+		eacl = history[i]
+	}
+
+	return attachEACLKey(ctx, ownerID, eacl)
+}
+
+// attachEACLKey sets public key of the eACL signature if it was not revoked
+// from neofs id.
+func attachEACLKey(ctx storage.Context, ownerID []byte, eacl extendedACL) extendedACL {
 	if len(eacl.sig) == 0 {
 		return eacl
 	}
 
-	// attach corresponding public key if it was not revoked from neofs id
-
-	neofsIDContractAddr := storage.Get(ctx, neofsIDContractKey).(interop.Hash160)
+	neofsIDContractAddr := common.ContractAddress(ctx, common.NeoFSIDContractName, neofsIDContractKey)
 	keys := contract.Call(neofsIDContractAddr, "key", contract.ReadOnly, ownerID).([]interop.PublicKey)
 
 	for i := range keys {
@@ -335,6 +1144,7 @@ func EACL(containerID []byte) extendedACL {
 
 func PutContainerSize(epoch int, cid []byte, usedSize int, pubKey interop.PublicKey) bool {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "putContainerSize", userMethods)
 
 	if !runtime.CheckWitness(pubKey) {
 		panic("container: invalid witness for size estimation")
@@ -344,29 +1154,57 @@ func PutContainerSize(epoch int, cid []byte, usedSize int, pubKey interop.Public
 		panic("container: only storage nodes can save size estimations")
 	}
 
-	key := estimationKey(epoch, cid)
-	s := getContainerSizeEstimation(ctx, key, cid)
-
-	// do not add estimation twice
-	for i := range s.estimations {
-		est := s.estimations[i]
-		if common.BytesEqual(est.from, pubKey) {
-			return false
-		}
+	if isEstimationStopped(ctx, epoch) {
+		panic("container: estimation of the epoch is stopped")
 	}
 
-	s.estimations = append(s.estimations, estimation{
-		from: pubKey,
-		size: usedSize,
-	})
-
-	storage.Put(ctx, key, std.Serialize(s))
+	if !addEstimation(ctx, epoch, cid, usedSize, pubKey) {
+		return false
+	}
 
 	runtime.Log("container: saved container size estimation")
 
 	return true
 }
 
+// PutContainerSizes saves size estimations of multiple containers of the
+// epoch made by the storage node. Returns amount of saved estimations,
+// estimations that were already saved are skipped.
+func PutContainerSizes(epoch int, sizes []containerSize, pubKey interop.PublicKey) int {
+	ctx := storage.GetContext()
+	// batch submission is paused together with single estimation submission
+	common.CheckPaused(ctx, "putContainerSize", userMethods)
+
+	if !runtime.CheckWitness(pubKey) {
+		panic("container: invalid witness for size estimation")
+	}
+
+	if !isStorageNode(ctx, pubKey) {
+		panic("container: only storage nodes can save size estimations")
+	}
+
+	if isEstimationStopped(ctx, epoch) {
+		panic("container: estimation of the epoch is stopped")
+	}
+
+	saved := 0
+
+	for i := 0; i < len(sizes); i++ {
+		// estimation keys are cleaned up assuming 32-byte container IDs
+		if len(sizes[i].cid) != 32 {
+			panic("container: incorrect container ID")
+		}
+
+		if addEstimation(ctx, epoch, sizes[i].cid, sizes[i].size, pubKey) {
+			saved++
+		}
+	}
+
+	runtime.Log("container: saved container size estimations")
+
+	return saved
+}
+
 func GetContainerSize(id []byte) containerSizes {
 	ctx := storage.GetReadOnlyContext()
 	return getContainerSizeEstimation(ctx, id, nil)
@@ -392,185 +1230,960 @@ func ListContainerSizes(epoch int) [][]byte {
 	return result
 }
 
-func NewEpoch(epochNum int) {
+// SetOwnerQuota sets maximum amount of containers of the owner overriding
+// network-wide default. Zero quota means no limit, negative quota removes
+// override.
+func SetOwnerQuota(owner []byte, quota int) bool {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "setOwnerQuota", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+	if len(owner) != 25 {
+		panic("setOwnerQuota: incorrect owner")
+	}
 
-	if notaryDisabled {
-		indirectCall := common.FromKnownContract(
-			ctx,
-			runtime.GetCallingScriptHash(),
-			netmapContractKey,
-		)
-		if !indirectCall {
-			panic("newEpoch: this method must be invoked from inner ring")
-		}
-	} else {
+	if !common.AlphabetDecision(ctx, notaryDisabled, "setOwnerQuota", []interface{}{owner, quota}) {
+		return true
+	}
+
+	if quota < 0 {
+		storage.Delete(ctx, quotaKey(owner))
+	} else {
+		storage.Put(ctx, quotaKey(owner), quota)
+	}
+
+	runtime.Notify("OwnerQuotaSet", owner, quota)
+
+	return true
+}
+
+// OwnerQuota returns maximum amount of containers of the owner. Zero quota
+// means no limit.
+func OwnerQuota(owner []byte) int {
+	ctx := storage.GetReadOnlyContext()
+	return getOwnerQuota(ctx, owner)
+}
+
+func NewEpoch(epochNum int) {
+	ctx := storage.GetContext()
+	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+	if notaryDisabled {
+		indirectCall := common.FromKnownContract(
+			ctx,
+			runtime.GetCallingScriptHash(),
+			common.NetmapContractName,
+			netmapContractKey,
+		)
+		if !indirectCall {
+			panic("newEpoch: this method must be invoked from inner ring")
+		}
+	} else {
 		multiaddr := common.AlphabetAddress()
 		if !runtime.CheckWitness(multiaddr) {
 			panic("newEpoch: this method must be invoked from inner ring")
 		}
 	}
 
-	candidates := keysToDelete(ctx, epochNum)
-	for _, candidate := range candidates {
-		storage.Delete(ctx, candidate)
+	candidates := keysToDelete(ctx, epochNum)
+	for _, candidate := range candidates {
+		storage.Delete(ctx, candidate)
+	}
+
+	last := lastProcessedEpoch(ctx, epochNum)
+
+	candidates = deletedToRelease(ctx, last, epochNum)
+	for _, candidate := range candidates {
+		storage.Delete(ctx, candidate)
+	}
+
+	candidates = sizesToDelete(ctx, last, epochNum)
+	for _, candidate := range candidates {
+		storage.Delete(ctx, candidate)
+	}
+
+	// signed requests are valid only at the epoch they were made at
+	for e := last; e < epochNum; e++ {
+		candidates = scheduledKeys(ctx, usedMessagePrefix, e)
+		for _, candidate := range candidates {
+			storage.Delete(ctx, candidate)
+		}
+	}
+
+	cleanupEACLHistory(ctx, last, epochNum)
+	processExpired(ctx, last, epochNum)
+
+	if epochNum > last {
+		storage.Put(ctx, lastEpochKey, epochNum)
+	}
+}
+
+func StartContainerEstimation(epoch int) bool {
+	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "startContainerEstimation", userMethods)
+	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+	var ( // for invocation collection without notary
+		alphabet []common.IRNode
+		nodeKey  []byte
+	)
+
+	if notaryDisabled {
+		alphabet = common.AlphabetNodes()
+		nodeKey = common.InnerRingInvoker(alphabet)
+		if len(nodeKey) == 0 {
+			panic("startEstimation: only inner ring nodes can invoke this")
+		}
+	} else {
+		multiaddr := common.AlphabetAddress()
+		if !runtime.CheckWitness(multiaddr) {
+			panic("startEstimation: only inner ring nodes can invoke this")
+		}
+	}
+
+	if notaryDisabled {
+		id := common.InvokeID([]interface{}{epoch}, []byte("startEstimation"))
+
+		if !common.Vote(ctx, "startContainerEstimation", id, nodeKey, len(alphabet)) {
+			return true
+		}
+
+		common.RemoveVotes(ctx, id)
+	}
+
+	runtime.Notify("StartEstimation", epoch)
+	runtime.Log("startEstimation: notification has been produced")
+
+	return true
+}
+
+func StopContainerEstimation(epoch int) bool {
+	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "stopContainerEstimation", userMethods)
+	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+	var ( // for invocation collection without notary
+		alphabet []common.IRNode
+		nodeKey  []byte
+	)
+
+	if notaryDisabled {
+		alphabet = common.AlphabetNodes()
+		nodeKey = common.InnerRingInvoker(alphabet)
+		if len(nodeKey) == 0 {
+			panic("stopEstimation: only inner ring nodes can invoke this")
+		}
+	} else {
+		multiaddr := common.AlphabetAddress()
+		if !runtime.CheckWitness(multiaddr) {
+			panic("stopEstimation: only inner ring nodes can invoke this")
+		}
+	}
+
+	if notaryDisabled {
+		id := common.InvokeID([]interface{}{epoch}, []byte("stopEstimation"))
+
+		if !common.Vote(ctx, "stopContainerEstimation", id, nodeKey, len(alphabet)) {
+			return true
+		}
+
+		common.RemoveVotes(ctx, id)
+	}
+
+	// aggregated sizes are frozen: estimations of the epoch are rejected
+	// from now on, see isEstimationStopped
+	if !isEstimationStopped(ctx, epoch) {
+		storage.Put(ctx, stoppedEstimationKey, epoch)
+	}
+
+	runtime.Notify("StopEstimation", epoch)
+	runtime.Log("stopEstimation: notification has been produced")
+
+	return true
+}
+
+// GetAggregatedSize returns median of container size estimations of the
+// epoch. Aggregated size is available only after estimation of the epoch is
+// stopped, so it doesn't change once returned.
+func GetAggregatedSize(cid []byte, epoch int) int {
+	ctx := storage.GetReadOnlyContext()
+
+	if !isEstimationStopped(ctx, epoch) {
+		return 0
+	}
+
+	size := storage.Get(ctx, sizeKey(epoch, cid))
+	if size == nil {
+		return 0
+	}
+
+	return size.(int)
+}
+
+// ListAggregatedSizes returns aggregated container sizes of the recent epochs
+// which estimation is stopped.
+func ListAggregatedSizes(cid []byte) []aggregatedSize {
+	ctx := storage.GetReadOnlyContext()
+
+	var (
+		result  []aggregatedSize
+		current = currentEpoch(ctx)
+	)
+
+	for epoch := current - sizeHistoryDepth; epoch <= current; epoch++ {
+		if !isEstimationStopped(ctx, epoch) {
+			break
+		}
+
+		size := storage.Get(ctx, sizeKey(epoch, cid))
+		if size != nil {
+			result = append(result, aggregatedSize{
+				epoch: epoch,
+				size:  size.(int),
+			})
+		}
+	}
+
+	return result
+}
+
+// SetNotaryDisabled switches the way to collect signatures of alphabet
+// nodes. If notary gets disabled, then ballot storage is initialized,
+// otherwise all pending ballots are removed.
+func SetNotaryDisabled(notaryDisabled bool) bool {
+	ctx := storage.GetContext()
+	currentDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+	if notaryDisabled == currentDisabled {
+		panic("setNotaryDisabled: notary mode is already set")
+	}
+
+	if !common.AlphabetDecision(ctx, currentDisabled, "setNotaryDisabled", []interface{}{}) {
+		return true
+	}
+
+	storage.Put(ctx, notaryDisabledKey, notaryDisabled)
+	if notaryDisabled {
+		common.InitVote(ctx)
+		runtime.Log("setNotaryDisabled: notary disabled")
+	} else {
+		common.PurgeVotes(ctx)
+		runtime.Log("setNotaryDisabled: notary enabled")
+	}
+
+	runtime.Notify("NotaryModeChanged", notaryDisabled)
+
+	return true
+}
+
+// RevokeVote removes vote of the invoking alphabet node from the pending
+// ballot with provided ID, if the decision has not reached quorum yet.
+func RevokeVote(id []byte) bool {
+	ctx := storage.GetContext()
+
+	alphabet := common.AlphabetNodes()
+	nodeKey := common.InnerRingInvoker(alphabet)
+	if len(nodeKey) == 0 {
+		panic("revokeVote: this method must be invoked by alphabet nodes")
+	}
+
+	if !common.RevokeVote(ctx, id, nodeKey) {
+		panic("revokeVote: there is no pending vote of the invoker")
+	}
+
+	runtime.Notify("VoteRevoked", id, nodeKey)
+	runtime.Log("revokeVote: vote has been revoked")
+
+	return true
+}
+
+// ListBallots returns list of pending ballots of the decisions made
+// without notary.
+func ListBallots() []common.BallotState {
+	ctx := storage.GetReadOnlyContext()
+	return common.Ballots(ctx)
+}
+
+// PurgeBallots removes at most limit expired ballots of the decisions made
+// without notary and returns amount of removed ballots. Only expired ballots
+// are removed, so method can be invoked by anyone.
+func PurgeBallots(limit int) int {
+	ctx := storage.GetContext()
+
+	if limit <= 0 {
+		panic("purgeBallots: invalid limit")
+	}
+
+	return common.PurgeExpiredVotes(ctx, limit)
+}
+
+// GetBallot returns pending ballot of the decision with provided ID.
+func GetBallot(id []byte) common.BallotState {
+	ctx := storage.GetReadOnlyContext()
+	return common.GetBallot(ctx, id)
+}
+
+// SetVotePolicy sets ballot expiry in blocks and quorum fraction of the
+// decisions made by contract method without notary. If method is empty,
+// then policy is set for all contract methods without own policy.
+func SetVotePolicy(method string, expiry, numerator, denominator int) bool {
+	ctx := storage.GetContext()
+	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+	policy := common.VotePolicy{
+		Expiry:      expiry,
+		Numerator:   numerator,
+		Denominator: denominator,
+	}
+
+	if !common.ValidVotePolicy(policy) {
+		panic("setVotePolicy: invalid vote policy")
+	}
+
+	if !common.AlphabetDecision(ctx, notaryDisabled, "setVotePolicy", []interface{}{method, expiry, numerator, denominator}) {
+		return true
+	}
+
+	common.SetVotePolicy(ctx, method, policy)
+	runtime.Log("setVotePolicy: vote policy has been updated")
+
+	return true
+}
+
+// VotePolicy returns vote policy of the decisions made by contract method
+// without notary.
+func VotePolicy(method string) common.VotePolicy {
+	ctx := storage.GetReadOnlyContext()
+	return common.GetVotePolicy(ctx, method)
+}
+
+func Version() int {
+	return version
+}
+
+// migrateStorage converts contract storage of the previous version to the
+// actual layout.
+func migrateStorage(ctx storage.Context, prev int) {
+	if prev < 2 {
+		// ballots are stored under their own keys since version 2
+		common.InitVote(ctx)
+	}
+
+	if prev < 4 {
+		// containers are indexed by container ID since version 3 and by
+		// owner ID prefix since version 4, indices are built in batches
+		// by MigrateIndex
+		common.SetSerialized(ctx, indexMigrationKey, indexMigration{
+			owners: common.GetList(ctx, ownersKey),
+		})
+	}
+
+	if prev < 5 {
+		// eACL keeps the epoch it was set at since version 5, the epoch of
+		// already stored eACLs is unknown, so it is considered to be zero
+		it := storage.Find(ctx, eACLPrefix, storage.None)
+		for iterator.Next(it) {
+			kv := iterator.Value(it).([]interface{})
+			fields := std.Deserialize(kv[1].([]byte)).([]interface{})
+
+			rule := extendedACL{
+				val: fields[0].([]byte),
+				sig: fields[1].([]byte),
+				pub: fields[2].([]byte),
+			}
+
+			key := kv[0].([]byte)
+			common.SetSerialized(ctx, key, rule)
+			addEACLHistory(ctx, key[len(eACLPrefix):], rule)
+			storage.Put(ctx, eACLHashKey(key[len(eACLPrefix):]), crypto.Sha256(rule.val))
+		}
+	}
+}
+
+func addContainer(ctx storage.Context, id []byte, owner []byte, container []byte) {
+	addOrAppend(ctx, ownersKey, owner)
+	addOrAppend(ctx, owner, id)
+	storage.Put(ctx, ownerKey(id), owner)
+	storage.Put(ctx, byOwnerKey(owner, id), id)
+	storage.Put(ctx, id, container)
+}
+
+func removeContainer(ctx storage.Context, id []byte, owner []byte) {
+	n := remove(ctx, owner, id)
+
+	// if it was last container, remove owner from the list of owners
+	if n == 0 {
+		_ = remove(ctx, ownersKey, owner)
+	}
+
+	removeName(ctx, id)
+	storage.Delete(ctx, ownerKey(id))
+	storage.Delete(ctx, byOwnerKey(owner, id))
+	expiration := storage.Get(ctx, expirationKey(id))
+	if expiration != nil {
+		storage.Delete(ctx, expiryIndexKey(expiration.(int), id))
+		storage.Delete(ctx, expirationKey(id))
+	}
+	storage.Delete(ctx, feeKey(id))
+	storage.Delete(ctx, append(eACLPrefix, id...))
+	storage.Delete(ctx, eACLHistoryKey(id))
+	storage.Delete(ctx, eACLHashKey(id))
+
+	var (
+		epoch    = currentEpoch(ctx)
+		grace    = netmapConfig(ctx, deletionGraceKey)
+		lifetime = netmapConfig(ctx, deletedEpochsKey)
+	)
+
+	// container structure is kept during deletion grace period
+	if grace == 0 {
+		storage.Delete(ctx, id)
+	} else {
+		storage.Put(ctx, releaseKey(releaseCnrPrefix, epoch+grace+1, id), true)
+	}
+
+	// deletion mark outlives the container structure
+	if lifetime < grace {
+		lifetime = grace
+	}
+
+	// remember deletion epoch to prevent container ID reuse
+	storage.Put(ctx, deletedKey(id), epoch)
+	storage.Put(ctx, releaseKey(releaseMarkPrefix, epoch+lifetime+1, id), true)
+}
+
+func nameKey(name, zone string) []byte {
+	return append([]byte(nameKeyPrefix), crypto.Sha256([]byte(name+"."+zone))...)
+}
+
+func addName(ctx storage.Context, id []byte, name, zone string) {
+	storage.Put(ctx, nameKey(name, zone), id)
+	storage.Put(ctx, append([]byte(cidNameKeyPrefix), id...), name+"."+zone)
+}
+
+func removeName(ctx storage.Context, id []byte) {
+	key := append([]byte(cidNameKeyPrefix), id...)
+
+	fqdn := storage.Get(ctx, key)
+	if fqdn == nil {
+		return
+	}
+
+	storage.Delete(ctx, append([]byte(nameKeyPrefix), crypto.Sha256(fqdn.([]byte))...))
+	storage.Delete(ctx, key)
+}
+
+// checkName returns the reason why container can't be registered with the
+// name in the zone, or empty string if it can.
+func checkName(ctx storage.Context, name, zone string) string {
+	if !isValidLabels(name, false) || !isValidLabels(zone, true) {
+		return "invalid container name format"
+	}
+
+	if storage.Get(ctx, nameKey(name, zone)) != nil {
+		return "container name is already taken"
+	}
+
+	return ""
+}
+
+// isValidLabels checks that s consists of lowercase letters, digits and
+// hyphens, which are not the first or the last symbol of the label. Labels
+// are separated by dots if multiple labels are allowed.
+func isValidLabels(s string, multiple bool) bool {
+	if len(s) == 0 || len(s) > maxNameLength {
+		return false
+	}
+
+	start := 0
+	for i := 0; i <= len(s); i++ {
+		if i == len(s) || (multiple && s[i] == '.') {
+			if i == start || s[start] == '-' || s[i-1] == '-' {
+				return false
+			}
+
+			start = i + 1
+
+			continue
+		}
+
+		c := s[i]
+		if !(c >= 'a' && c <= 'z') && !(c >= '0' && c <= '9') && c != '-' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func deletedKey(id []byte) []byte {
+	return append([]byte(deletedKeyPrefix), id...)
+}
+
+func releaseKey(prefix string, epoch int, id []byte) []byte {
+	var buf interface{} = epoch
+
+	result := append([]byte(prefix), buf.([]byte)...)
+
+	return append(result, id...)
+}
+
+// checkUniqueID returns the reason why container ID can't be used for the new
+// container, or empty string if it can.
+func checkUniqueID(ctx storage.Context, id []byte) string {
+	if storage.Get(ctx, deletedKey(id)) != nil {
+		return "container was recently deleted"
+	}
+
+	if storage.Get(ctx, id) != nil {
+		return "container already exists"
+	}
+
+	return ""
+}
+
+func currentEpoch(ctx storage.Context) int {
+	netmapContractAddr := common.ContractAddress(ctx, common.NetmapContractName, netmapContractKey)
+	return contract.Call(netmapContractAddr, "epoch", contract.ReadOnly).(int)
+}
+
+// deletedToRelease returns keys of deleted containers and deleted container
+// marks scheduled for release at the epochs in (last, epoch], along with the
+// keys that scheduled them.
+func deletedToRelease(ctx storage.Context, last, epoch int) [][]byte {
+	var result [][]byte
+
+	for e := last + 1; e <= epoch; e++ {
+		keys := scheduledKeys(ctx, releaseCnrPrefix, e)
+		for i := range keys {
+			key := keys[i]
+			result = append(result, key[len(key)-32:], key)
+		}
+
+		keys = scheduledKeys(ctx, releaseMarkPrefix, e)
+		for i := range keys {
+			key := keys[i]
+			result = append(result, deletedKey(key[len(key)-32:]), key)
+		}
+	}
+
+	return result
+}
+
+// netmapConfig returns integer value of netmap contract configuration or zero
+// if it is not set.
+func netmapConfig(ctx storage.Context, key string) int {
+	netmapContractAddr := common.ContractAddress(ctx, common.NetmapContractName, netmapContractKey)
+
+	data := contract.Call(netmapContractAddr, "config", contract.ReadOnly, key)
+	if data == nil {
+		return 0
+	}
+
+	return data.(int)
+}
+
+// chargeDeletion transfers container deletion fee from the owner to the
+// alphabet nodes and refunds part of the container creation fee back from
+// the nodes that received it. Refund of the node with insufficient balance
+// is skipped, so it can't block container deletion.
+func chargeDeletion(ctx storage.Context, alphabet []common.IRNode, id, owner []byte) {
+	var (
+		balanceContractAddr = common.ContractAddress(ctx, common.BalanceContractName, balanceContractKey)
+		ownerAddr           = common.WalletToScriptHash(owner)
+		deleteFee           = netmapConfig(ctx, deleteFeeKey)
+	)
+
+	if deleteFee > 0 {
+		for i := 0; i < len(alphabet); i++ {
+			node := contract.CreateStandardAccount(alphabet[i].PublicKey)
+
+			tx := contract.Call(balanceContractAddr, "transferX", contract.All,
+				ownerAddr, node, deleteFee, common.ContainerDeleteFeeTransferDetails(id))
+			if !tx.(bool) {
+				panic("delete: can't transfer assets for container deletion")
+			}
+		}
+	}
+
+	data := storage.Get(ctx, feeKey(id))
+	if data == nil {
+		return
+	}
+
+	fee := std.Deserialize(data.([]byte)).(paidFee)
+
+	refund := fee.amount * netmapConfig(ctx, refundPercentKey) / 100
+	if refund <= 0 {
+		return
+	}
+
+	for i := 0; i < len(fee.nodes); i++ {
+		tx := contract.Call(balanceContractAddr, "transferX", contract.All,
+			fee.nodes[i], ownerAddr, refund, common.ContainerRefundTransferDetails(id))
+		if !tx.(bool) {
+			runtime.Log("delete: can't refund assets for container deletion")
+		}
+	}
+}
+
+func feeKey(id []byte) []byte {
+	return append([]byte(feeKeyPrefix), id...)
+}
+
+func addOrAppend(ctx storage.Context, key interface{}, value []byte) {
+	list := common.GetList(ctx, key)
+	for i := 0; i < len(list); i++ {
+		if common.BytesEqual(list[i], value) {
+			return
+		}
+	}
+
+	if len(list) == 0 {
+		list = [][]byte{value}
+	} else {
+		list = append(list, value)
+	}
+
+	common.SetSerialized(ctx, key, list)
+}
+
+// remove returns amount of left elements in the list
+func remove(ctx storage.Context, key interface{}, value []byte) int {
+	var (
+		list    = common.GetList(ctx, key)
+		newList = [][]byte{}
+	)
+
+	for i := 0; i < len(list); i++ {
+		if !common.BytesEqual(list[i], value) {
+			newList = append(newList, list[i])
+		}
+	}
+
+	ln := len(newList)
+	if ln == 0 {
+		storage.Delete(ctx, key)
+	} else {
+		common.SetSerialized(ctx, key, newList)
+	}
+
+	return ln
+}
+
+func getAllContainers(ctx storage.Context) [][]byte {
+	var list [][]byte
+
+	// containers are not indexed by owner until MigrateIndex is finished
+	if storage.Get(ctx, indexMigrationKey) != nil {
+		owners := common.GetList(ctx, ownersKey)
+		for i := 0; i < len(owners); i++ {
+			containers := common.GetList(ctx, owners[i])
+			for j := 0; j < len(containers); j++ {
+				list = append(list, containers[j])
+			}
+		}
+
+		return list
+	}
+
+	it := storage.Find(ctx, []byte(byOwnerKeyPrefix), storage.ValuesOnly)
+	for iterator.Next(it) {
+		list = append(list, iterator.Value(it).([]byte))
+	}
+
+	return list
+}
+
+// usedMessageKey returns storage key of the signed user request valid only at
+// the epoch. Signatures are malleable, so requests are identified by the hash
+// of signed message. Keys are removed after the epoch, see NewEpoch.
+func usedMessageKey(epoch int, msg []byte) []byte {
+	var buf interface{} = epoch
+
+	result := append([]byte(usedMessagePrefix), buf.([]byte)...)
+
+	return append(result, crypto.Sha256(msg)...)
+}
+
+func isUsedMessage(ctx storage.Context, epoch int, msg []byte) bool {
+	return storage.Get(ctx, usedMessageKey(epoch, msg)) != nil
+}
+
+func markUsedMessage(ctx storage.Context, epoch int, msg []byte) {
+	storage.Put(ctx, usedMessageKey(epoch, msg), true)
+}
+
+func eACLHashKey(cid []byte) []byte {
+	return append([]byte(eACLHashPrefix), cid...)
+}
+
+// isUsedEACL returns true if eACL has already been set for the container.
+// eACL has no nonce, so only the eACLs of the container history and the
+// latest set eACL are remembered.
+func isUsedEACL(ctx storage.Context, cid, eACL []byte) bool {
+	data := storage.Get(ctx, eACLHashKey(cid))
+	if data != nil && common.BytesEqual(data.([]byte), crypto.Sha256(eACL)) {
+		return true
+	}
+
+	history := getEACLHistory(ctx, cid)
+	for i := range history {
+		if common.BytesEqual(history[i].val, eACL) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getContainerFee returns fee charged by every alphabet node for the
+// container creation. Fee of the container tier replaces default container
+// fee, fee per replica is added for every object replica in placement policy.
+// Returns -1 if container tier is unknown.
+func getContainerFee(ctx storage.Context, container []byte) int {
+	netmapContractAddr := common.ContractAddress(ctx, common.NetmapContractName, netmapContractKey)
+
+	fee := 0
+
+	tier := common.ContainerAttribute(container, tierAttribute)
+	if len(tier) != 0 {
+		data := contract.Call(netmapContractAddr, "config", contract.ReadOnly, tierFeeKeyPrefix+tier)
+		if data == nil {
+			return -1
+		}
+
+		fee = data.(int)
+	} else {
+		fee = contract.Call(netmapContractAddr, "config", contract.ReadOnly, containerFeeKey).(int)
+	}
+
+	data := contract.Call(netmapContractAddr, "config", contract.ReadOnly, replicaFeeKey)
+	if data != nil {
+		fee += data.(int) * common.ContainerReplicas(container)
 	}
+
+	return fee
 }
 
-func StartContainerEstimation(epoch int) bool {
-	ctx := storage.GetContext()
-	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+func quotaKey(owner []byte) []byte {
+	return append([]byte(quotaKeyPrefix), owner...)
+}
 
-	var ( // for invocation collection without notary
-		alphabet []common.IRNode
-		nodeKey  []byte
-	)
+func getOwnerQuota(ctx storage.Context, owner []byte) int {
+	quota := storage.Get(ctx, quotaKey(owner))
+	if quota != nil {
+		return quota.(int)
+	}
 
-	if notaryDisabled {
-		alphabet = common.AlphabetNodes()
-		nodeKey = common.InnerRingInvoker(alphabet)
-		if len(nodeKey) == 0 {
-			panic("startEstimation: only inner ring nodes can invoke this")
-		}
-	} else {
-		multiaddr := common.AlphabetAddress()
-		if !runtime.CheckWitness(multiaddr) {
-			panic("startEstimation: only inner ring nodes can invoke this")
-		}
+	return netmapConfig(ctx, maxContainersKey)
+}
+
+// isQuotaExceeded returns true if owner can't have one more container.
+func isQuotaExceeded(ctx storage.Context, owner []byte) bool {
+	quota := getOwnerQuota(ctx, owner)
+	if quota == 0 {
+		return false
 	}
 
-	if notaryDisabled {
-		threshold := len(alphabet)*2/3 + 1
-		id := common.InvokeID([]interface{}{epoch}, []byte("startEstimation"))
+	return len(common.GetList(ctx, owner)) >= quota
+}
 
-		n := common.Vote(ctx, id, nodeKey)
-		if n < threshold {
-			return true
-		}
+func expirationKey(id []byte) []byte {
+	return append([]byte(expirationPrefix), id...)
+}
 
-		common.RemoveVotes(ctx, id)
-	}
+// expiryIndexKey returns key of the container in the index of containers
+// by expiration epoch.
+func expiryIndexKey(epoch int, id []byte) []byte {
+	var buf interface{} = epoch
 
-	runtime.Notify("StartEstimation", epoch)
-	runtime.Log("startEstimation: notification has been produced")
+	result := append([]byte(expiryIndexPrefix), buf.([]byte)...)
 
-	return true
+	return append(result, id...)
 }
 
-func StopContainerEstimation(epoch int) bool {
-	ctx := storage.GetContext()
-	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+// checkExpiration returns expiration epoch of the container, or zero if
+// container does not expire, and the reason why container can't be created
+// with it.
+func checkExpiration(ctx storage.Context, container []byte) (int, string) {
+	value := common.ContainerAttribute(container, expirationAttribute)
+	if len(value) == 0 {
+		return 0, ""
+	}
 
-	var ( // for invocation collection without notary
-		alphabet []common.IRNode
-		nodeKey  []byte
-	)
+	// std.Atoi panics on invalid input, so digits are checked beforehand
+	digits := []byte(value)
+	if len(digits) > maxEpochDigits {
+		return 0, "invalid expiration epoch"
+	}
 
-	if notaryDisabled {
-		alphabet = common.AlphabetNodes()
-		nodeKey = common.InnerRingInvoker(alphabet)
-		if len(nodeKey) == 0 {
-			panic("stopEstimation: only inner ring nodes can invoke this")
-		}
-	} else {
-		multiaddr := common.AlphabetAddress()
-		if !runtime.CheckWitness(multiaddr) {
-			panic("stopEstimation: only inner ring nodes can invoke this")
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return 0, "invalid expiration epoch"
 		}
 	}
 
-	if notaryDisabled {
-		threshold := len(alphabet)*2/3 + 1
-		id := common.InvokeID([]interface{}{epoch}, []byte("stopEstimation"))
+	expiration := std.Atoi(value, 10)
+	if expiration <= currentEpoch(ctx) {
+		return 0, "container is already expired"
+	}
 
-		n := common.Vote(ctx, id, nodeKey)
-		if n < threshold {
-			return true
+	return expiration, ""
+}
+
+// processExpired notifies about containers expired at the epochs in
+// (last, epoch] and removes containers which grace period is over. Deletion
+// fee and refund are not applied here: removal is not requested by the owner,
+// so there is no owner signature to charge the fee, and the creation fee is
+// refunded only for containers deleted before the end of their lifetime.
+func processExpired(ctx storage.Context, last, epoch int) {
+	grace := netmapConfig(ctx, expirationGraceKey)
+
+	// notify once, at the first processed epoch after container lifetime
+	for exp := last; exp < epoch; exp++ {
+		keys := scheduledKeys(ctx, expiryIndexPrefix, exp)
+		for i := range keys {
+			key := keys[i]
+			runtime.Notify("ContainerExpired", key[len(key)-32:], exp)
 		}
+	}
 
-		common.RemoveVotes(ctx, id)
+	// grace period may change, so removal continues from the latest removed
+	// expiration epoch instead of the last processed one
+	removed := last - grace - 1
+	data := storage.Get(ctx, lastRemovedExpirationKey)
+	if data != nil {
+		removed = data.(int)
 	}
 
-	runtime.Notify("StopEstimation", epoch)
-	runtime.Log("stopEstimation: notification has been produced")
+	for exp := removed + 1; exp+grace < epoch; exp++ {
+		keys := scheduledKeys(ctx, expiryIndexPrefix, exp)
+		for i := range keys {
+			key := keys[i]
+			id := key[len(key)-32:]
+			removeContainer(ctx, id, getOwnerByID(ctx, id))
+			runtime.Notify("ExpiredContainerRemoved", id)
+		}
+	}
 
-	return true
+	if epoch-grace-1 > removed {
+		storage.Put(ctx, lastRemovedExpirationKey, epoch-grace-1)
+	}
 }
 
-func Version() int {
-	return version
+func eACLHistoryKey(cid []byte) []byte {
+	return append([]byte(eACLHistoryPrefix), cid...)
 }
 
-func addContainer(ctx storage.Context, id []byte, owner []byte, container []byte) {
-	addOrAppend(ctx, ownersKey, owner)
-	addOrAppend(ctx, owner, id)
-	storage.Put(ctx, id, container)
+func getEACLHistory(ctx storage.Context, cid []byte) []extendedACL {
+	data := storage.Get(ctx, eACLHistoryKey(cid))
+	if data != nil {
+		return std.Deserialize(data.([]byte)).([]extendedACL)
+	}
+
+	return []extendedACL{}
 }
 
-func removeContainer(ctx storage.Context, id []byte, owner []byte) {
-	n := remove(ctx, owner, id)
+// addEACLHistory appends eACL to the container history. eACL set at the same
+// epoch replaces the previous one.
+func addEACLHistory(ctx storage.Context, cid []byte, rule extendedACL) {
+	history := getEACLHistory(ctx, cid)
 
-	// if it was last container, remove owner from the list of owners
-	if n == 0 {
-		_ = remove(ctx, ownersKey, owner)
+	n := len(history)
+	if n > 0 && history[n-1].epoch == rule.epoch {
+		history[n-1] = rule
+	} else {
+		history = append(history, rule)
 	}
 
-	storage.Delete(ctx, id)
-}
+	common.SetSerialized(ctx, eACLHistoryKey(cid), trimEACLHistory(history, len(history)-eACLHistorySize))
 
-func addOrAppend(ctx storage.Context, key interface{}, value []byte) {
-	list := common.GetList(ctx, key)
-	for i := 0; i < len(list); i++ {
-		if common.BytesEqual(list[i], value) {
-			return
-		}
+	// replaced eACL leaves the history after eACLHistoryDepth epochs
+	if n > 0 && history[n-1].epoch != rule.epoch {
+		storage.Put(ctx, eACLCleanupKey(rule.epoch+eACLHistoryDepth+1, cid), true)
 	}
+}
 
-	if len(list) == 0 {
-		list = [][]byte{value}
-	} else {
-		list = append(list, value)
+// lastProcessedEpoch returns the epoch of the previous NewEpoch call. Netmap
+// contract can skip epochs, so cleanups scheduled by epoch process every
+// epoch after it, not the new one only.
+func lastProcessedEpoch(ctx storage.Context, epoch int) int {
+	data := storage.Get(ctx, lastEpochKey)
+	if data == nil {
+		return epoch - 1
 	}
 
-	common.SetSerialized(ctx, key, list)
+	return data.(int)
 }
 
-// remove returns amount of left elements in the list
-func remove(ctx storage.Context, key interface{}, value []byte) int {
+// scheduledKeys returns keys stored under the prefix for the epoch that end
+// with 32-byte container ID or hash, see eACLCleanupKey, expiryIndexKey,
+// releaseKey and usedMessageKey.
+func scheduledKeys(ctx storage.Context, prefix string, epoch int) [][]byte {
 	var (
-		list    = common.GetList(ctx, key)
-		newList = [][]byte{}
+		buf         interface{} = epoch
+		epochPrefix             = append([]byte(prefix), buf.([]byte)...)
+		result      [][]byte
 	)
 
-	for i := 0; i < len(list); i++ {
-		if !common.BytesEqual(list[i], value) {
-			newList = append(newList, list[i])
+	it := storage.Find(ctx, epochPrefix, storage.KeysOnly)
+	for iterator.Next(it) {
+		key := iterator.Value(it).([]byte) // it MUST BE `storage.KeysOnly`
+
+		// skip keys of other epochs with the same prefix
+		if len(key) == len(epochPrefix)+32 {
+			result = append(result, key)
 		}
 	}
 
-	ln := len(newList)
-	if ln == 0 {
-		storage.Delete(ctx, key)
-	} else {
-		common.SetSerialized(ctx, key, newList)
-	}
+	return result
+}
 
-	return ln
+func eACLCleanupKey(epoch int, cid []byte) []byte {
+	var buf interface{} = epoch
+
+	result := append([]byte(eACLCleanupPrefix), buf.([]byte)...)
+
+	return append(result, cid...)
 }
 
-func getAllContainers(ctx storage.Context) [][]byte {
-	var list [][]byte
+// cleanupEACLHistory removes eACLs that were replaced more than
+// eACLHistoryDepth epochs ago. Only histories of the containers scheduled
+// for cleanup at the epochs in (last, epoch] are processed, see
+// addEACLHistory.
+func cleanupEACLHistory(ctx storage.Context, last, epoch int) {
+	for e := last + 1; e <= epoch; e++ {
+		keys := scheduledKeys(ctx, eACLCleanupPrefix, e)
+
+		for i := range keys {
+			key := keys[i]
+			cid := key[len(key)-32:]
+			history := getEACLHistory(ctx, cid)
+
+			start := 0
+			for start < len(history)-1 && epoch-history[start+1].epoch > eACLHistoryDepth {
+				start++
+			}
 
-	it := storage.Find(ctx, []byte{}, storage.KeysOnly)
-	for iterator.Next(it) {
-		key := iterator.Value(it).([]byte) // it MUST BE `storage.KeysOnly`
-		if len(key) == containerIDSize {
-			list = append(list, key)
+			if start > 0 {
+				common.SetSerialized(ctx, eACLHistoryKey(cid), trimEACLHistory(history, start))
+			}
+
+			storage.Delete(ctx, key)
 		}
 	}
+}
 
-	return list
+// trimEACLHistory returns history without first n eACLs.
+func trimEACLHistory(history []extendedACL, n int) []extendedACL {
+	if n <= 0 {
+		return history
+	}
+
+	result := []extendedACL{}
+	for i := n; i < len(history); i++ {
+		result = append(result, history[i])
+	}
+
+	return result
 }
 
 func getEACL(ctx storage.Context, cid []byte) extendedACL {
@@ -583,6 +2196,93 @@ func getEACL(ctx storage.Context, cid []byte) extendedACL {
 	return extendedACL{val: []byte{}, sig: interop.Signature{}, pub: interop.PublicKey{}}
 }
 
+// dropEACL removes eACL of the container transferred from the owner. eACL
+// signature can't be verified with the keys of the new owner, so eACLs of the
+// history keep the key of the previous owner.
+func dropEACL(ctx storage.Context, cid, owner []byte, epoch int) {
+	history := getEACLHistory(ctx, cid)
+	if len(history) == 0 {
+		return
+	}
+
+	for i := range history {
+		if len(history[i].pub) == 0 {
+			history[i] = attachEACLKey(ctx, owner, history[i])
+		}
+	}
+
+	common.SetSerialized(ctx, eACLHistoryKey(cid), history)
+
+	key := append(eACLPrefix, cid...)
+	if storage.Get(ctx, key) != nil {
+		storage.Delete(ctx, key)
+		addEACLHistory(ctx, cid, extendedACL{
+			val:   []byte{},
+			sig:   interop.Signature{},
+			pub:   interop.PublicKey{},
+			epoch: epoch,
+		})
+	}
+}
+
+// checkSession verifies NeoFS API container session token signed by the
+// owner and returns delegated key if the token allows the verb on the
+// container at the current epoch. Token body must be signed by the key the
+// owner ID is derived from or by the owner key registered in NeoFS ID
+// contract. Container ID is not checked for "put" verb.
+func checkSession(ctx storage.Context, token, tokenSignature []byte, verb string, owner, cid []byte) interop.PublicKey {
+	session := common.ContainerSessionToken(token)
+
+	if !common.BytesEqual(session.OwnerID, owner) {
+		panic(verb + ": session token is issued by another owner")
+	}
+
+	if session.Verb != sessionVerb(verb) {
+		panic(verb + ": session token does not allow the operation")
+	}
+
+	if len(cid) != 0 && len(session.ContainerID) != 0 && !common.BytesEqual(session.ContainerID, cid) {
+		panic(verb + ": session token is issued for another container")
+	}
+
+	// CryptoLib verifies ECDSA signatures over SHA-256 only, so tokens signed
+	// with default ECDSA_SHA512 scheme of NeoFS API can't be checked on-chain
+	if session.SignatureScheme != common.SignatureECDSARFC6979 {
+		panic(verb + ": session token must be signed with ECDSA_RFC6979_SHA256 scheme")
+	}
+
+	epoch := currentEpoch(ctx)
+	if epoch < session.NotBefore || epoch > session.Expiration {
+		panic(verb + ": session token is expired")
+	}
+
+	signerKey := session.SignerKey
+	if len(signerKey) == 0 || !isSignedByOwnerKey(session.Body, tokenSignature, owner, signerKey) {
+		neofsIDContractAddr := common.ContractAddress(ctx, common.NeoFSIDContractName, neofsIDContractKey)
+		keys := contract.Call(neofsIDContractAddr, "key", contract.ReadOnly, owner).([]interop.PublicKey)
+
+		if !verifySignature(session.Body, tokenSignature, keys) {
+			panic(verb + ": invalid session token signature")
+		}
+	}
+
+	return session.SessionKey
+}
+
+// sessionVerb returns session token verb of the container operation.
+func sessionVerb(verb string) int {
+	switch verb {
+	case "put":
+		return common.SessionVerbPut
+	case "delete":
+		return common.SessionVerbDelete
+	case "setEACL":
+		return common.SessionVerbSetEACL
+	}
+
+	return 0
+}
+
 func verifySignature(msg []byte, sig interop.Signature, keys []interop.PublicKey) bool {
 	for i := range keys {
 		key := keys[i]
@@ -594,21 +2294,90 @@ func verifySignature(msg []byte, sig interop.Signature, keys []interop.PublicKey
 	return false
 }
 
-func getOwnerByID(ctx storage.Context, id []byte) []byte {
-	owners := common.GetList(ctx, ownersKey)
-	for i := 0; i < len(owners); i++ {
-		ownerID := owners[i]
-		containers := common.GetList(ctx, ownerID)
+// listAfter returns at most limit keys stored under prefix that follow
+// prefix||cursor. Storage can't be searched from an arbitrary key, so keys
+// sharing the longest common part with the cursor are searched first and
+// the search is extended byte by byte. If there are more than limit keys
+// before the cursor at some byte, keys after it are searched by every
+// following byte value, so listing cost does not depend on the index size.
+func listAfter(ctx storage.Context, prefix, cursor []byte, limit int) [][]byte {
+	keys := [][]byte{}
+
+	if len(cursor) == 0 {
+		return appendKeys(ctx, keys, prefix, limit)
+	}
 
-		for j := 0; j < len(containers); j++ {
-			container := containers[j]
-			if common.BytesEqual(container, id) {
-				return ownerID
+	for k := len(cursor) - 1; k >= 0 && len(keys) < limit; k-- {
+		var (
+			base    = append(prefix, cursor[:k]...)
+			skipped = 0
+		)
+
+		it := storage.Find(ctx, base, storage.KeysOnly)
+		for len(keys) < limit && skipped <= limit && iterator.Next(it) {
+			key := iterator.Value(it).([]byte)
+			if len(key) > len(base) && key[len(base)] > cursor[k] {
+				keys = append(keys, key)
+			} else {
+				skipped++
+			}
+		}
+
+		if skipped > limit {
+			for b := int(cursor[k]) + 1; b < 256 && len(keys) < limit; b++ {
+				keys = appendKeys(ctx, keys, append(base, byte(b)), limit)
 			}
 		}
 	}
 
-	return nil
+	return keys
+}
+
+// appendKeys appends keys stored under prefix to the list until it contains
+// limit keys.
+func appendKeys(ctx storage.Context, keys [][]byte, prefix []byte, limit int) [][]byte {
+	it := storage.Find(ctx, prefix, storage.KeysOnly)
+	for len(keys) < limit && iterator.Next(it) {
+		keys = append(keys, iterator.Value(it).([]byte))
+	}
+
+	return keys
+}
+
+// checkIndexMigrated panics if containers are not indexed by owner yet, see
+// MigrateIndex.
+func checkIndexMigrated(ctx storage.Context, method string) {
+	if storage.Get(ctx, indexMigrationKey) != nil {
+		panic(method + ": container index migration is not finished")
+	}
+}
+
+func getOwnerByID(ctx storage.Context, id []byte) []byte {
+	owner := storage.Get(ctx, ownerKey(id))
+	if owner != nil {
+		return owner.([]byte)
+	}
+
+	// container may be not indexed yet, see MigrateIndex
+	if storage.Get(ctx, indexMigrationKey) == nil || storage.Get(ctx, deletedKey(id)) != nil {
+		return nil
+	}
+
+	container := storage.Get(ctx, id)
+	if container == nil {
+		return nil
+	}
+
+	return common.ContainerOwnerID(container.([]byte))
+}
+
+func ownerKey(id []byte) []byte {
+	return append([]byte(ownerKeyPrefix), id...)
+}
+
+func byOwnerKey(owner, id []byte) []byte {
+	key := append([]byte(byOwnerKeyPrefix), owner...)
+	return append(key, id...)
 }
 
 func isSignedByOwnerKey(msg []byte, sig interop.Signature, owner []byte, key interop.PublicKey) bool {
@@ -650,12 +2419,12 @@ func getContainerSizeEstimation(ctx storage.Context, key, cid []byte) containerS
 // isStorageNode looks into _previous_ epoch network map, because storage node
 // announce container size estimation of previous epoch.
 func isStorageNode(ctx storage.Context, key interop.PublicKey) bool {
-	netmapContractAddr := storage.Get(ctx, netmapContractKey).(interop.Hash160)
+	netmapContractAddr := common.ContractAddress(ctx, common.NetmapContractName, netmapContractKey)
 	snapshot := contract.Call(netmapContractAddr, "snapshot", contract.ReadOnly, 1).([]storageNode)
 
 	for i := range snapshot {
 		nodeInfo := snapshot[i].info
-		nodeKey := nodeInfo[2:35] // offset:2, len:33
+		nodeKey := common.NodeInfoKey(nodeInfo)
 
 		if common.BytesEqual(key, nodeKey) {
 			return true
@@ -665,6 +2434,95 @@ func isStorageNode(ctx storage.Context, key interop.PublicKey) bool {
 	return false
 }
 
+func sizeKey(epoch int, cid []byte) []byte {
+	var buf interface{} = epoch
+
+	result := append([]byte(sizeKeyPrefix), buf.([]byte)...)
+
+	return append(result, cid...)
+}
+
+// median returns median size of estimations.
+func median(estimations []estimation) int {
+	sizes := []int{}
+
+	// insertion sort, amount of estimations is bounded by netmap size
+	for i := 0; i < len(estimations); i++ {
+		size := estimations[i].size
+
+		sizes = append(sizes, size)
+
+		j := len(sizes) - 1
+		for j > 0 && sizes[j-1] > size {
+			sizes[j] = sizes[j-1]
+			j--
+		}
+
+		sizes[j] = size
+	}
+
+	n := len(sizes)
+	if n%2 == 1 {
+		return sizes[n/2]
+	}
+
+	return (sizes[n/2-1] + sizes[n/2]) / 2
+}
+
+// sizesToDelete returns keys of aggregated container sizes that became older
+// than sizeHistoryDepth epochs at the epochs in (last, epoch].
+func sizesToDelete(ctx storage.Context, last, epoch int) [][]byte {
+	var result [][]byte
+
+	for e := last + 1; e <= epoch; e++ {
+		keys := scheduledKeys(ctx, sizeKeyPrefix, e-sizeHistoryDepth-1)
+		for i := range keys {
+			result = append(result, keys[i])
+		}
+	}
+
+	return result
+}
+
+// isEstimationStopped returns true if estimation of the epoch has been stopped
+// and its aggregated container sizes are final.
+func isEstimationStopped(ctx storage.Context, epoch int) bool {
+	data := storage.Get(ctx, stoppedEstimationKey)
+	if data == nil {
+		return false
+	}
+
+	return epoch <= data.(int)
+}
+
+// addEstimation saves container size estimation of the storage node. Returns
+// false if estimation of the node has already been saved.
+func addEstimation(ctx storage.Context, epoch int, cid []byte, usedSize int, pubKey interop.PublicKey) bool {
+	key := estimationKey(epoch, cid)
+	s := getContainerSizeEstimation(ctx, key, cid)
+
+	// do not add estimation twice
+	for i := range s.estimations {
+		est := s.estimations[i]
+		if common.BytesEqual(est.from, pubKey) {
+			return false
+		}
+	}
+
+	s.estimations = append(s.estimations, estimation{
+		from: pubKey,
+		size: usedSize,
+	})
+
+	storage.Put(ctx, key, std.Serialize(s))
+
+	// aggregated size is updated with every estimation, so stop of the
+	// estimation freezes it without processing of all containers at once
+	storage.Put(ctx, sizeKey(epoch, cid), median(s.estimations))
+
+	return true
+}
+
 func keysToDelete(ctx storage.Context, epoch int) [][]byte {
 	results := [][]byte{}
 
@@ -26,8 +26,30 @@ package smart_contract
 	- ListConfig
 	- SetConfig
 
+	Ballot methods:
+	- ListBallots
+	- GetBallot
+	- RevokeVote
+	- SetVotePolicy
+	- VotePolicy
+
 	Other utility methods:
 	- Migrate
+	- SetNotaryDisabled
+	- ProposeOwner
+	- AcceptOwnership
+	- CancelOwnerProposal
+	- PendingOwner
+	- Migrations
+	- SetUpgradeDelay
+	- ProposeUpgrade
+	- ApproveUpgrade
+	- CancelUpgrade
+	- UpgradeProposal
+	- UpgradeDelay
+	- Pause
+	- Unpause
+	- IsPaused
 	- Version
 	- Cheque
 */
@@ -56,7 +78,7 @@ const (
 	candidateFeeConfigKey = "InnerRingCandidateFee"
 	withdrawFeeConfigKey  = "WithdrawFee"
 
-	version = 3
+	version = 4
 
 	alphabetKey       = "alphabet"
 	candidatesKey     = "candidates"
@@ -76,6 +98,24 @@ var (
 	configPrefix = []byte("config")
 )
 
+// Pausable methods of the contract, see Pause.
+var (
+	// userMethods are stopped by the pause of the whole contract.
+	userMethods = []string{"onNEP17Payment", "deposit", "withdraw", "bind", "unbind", "innerRingCandidateAdd", "innerRingCandidateRemove"}
+	// systemMethods are invoked by inner ring nodes, so they are stopped
+	// only by their own pause.
+	systemMethods = []string{"cheque", "alphabetUpdate", "setConfig"}
+)
+
+func _deploy(data interface{}, isUpdate bool) {
+	ctx := storage.GetContext()
+
+	prev := common.CommitVersion(ctx, version)
+	if isUpdate {
+		migrateStorage(ctx, prev)
+	}
+}
+
 // Init set up initial alphabet node keys.
 func Init(notaryDisabled bool, owner, addrProc interop.Hash160, args []interop.PublicKey) bool {
 	ctx := storage.GetContext()
@@ -84,6 +124,8 @@ func Init(notaryDisabled bool, owner, addrProc interop.Hash160, args []interop.P
 		panic("only owner can reinitialize contract")
 	}
 
+	common.CheckNotGoverned(ctx, "init")
+
 	var irList []common.IRNode
 
 	if len(args) == 0 {
@@ -123,19 +165,196 @@ func Init(notaryDisabled bool, owner, addrProc interop.Hash160, args []interop.P
 
 // Migrate updates smart contract execution script and manifest.
 func Migrate(script []byte, manifest []byte) bool {
-	ctx := storage.GetReadOnlyContext()
+	ctx := storage.GetContext()
 
 	if !common.HasUpdateAccess(ctx) {
 		runtime.Log("only owner can update contract")
 		return false
 	}
 
+	common.CheckUpgrade(ctx, script, manifest)
 	management.Update(script, manifest)
 	runtime.Log("neofs contract updated")
 
 	return true
 }
 
+// ProposeOwner proposes new contract owner. Ownership is transferred after
+// proposed owner accepts it.
+func ProposeOwner(owner interop.Hash160) bool {
+	ctx := storage.GetContext()
+	common.ProposeOwner(ctx, owner)
+
+	return true
+}
+
+// AcceptOwnership transfers contract ownership to the proposed owner.
+func AcceptOwnership() bool {
+	ctx := storage.GetContext()
+	common.AcceptOwnership(ctx)
+
+	runtime.Log("neofs contract owner changed")
+
+	return true
+}
+
+// CancelOwnerProposal removes proposed contract owner.
+func CancelOwnerProposal() bool {
+	ctx := storage.GetContext()
+	common.CancelOwnerProposal(ctx)
+
+	return true
+}
+
+// PendingOwner returns proposed contract owner if there is one.
+func PendingOwner() interop.Hash160 {
+	ctx := storage.GetReadOnlyContext()
+	return common.PendingOwner(ctx)
+}
+
+// Migrations returns history of contract deploy and updates.
+func Migrations() []common.Migration {
+	ctx := storage.GetReadOnlyContext()
+	return common.Migrations(ctx)
+}
+
+// SetUpgradeDelay sets amount of blocks between alphabet approval and
+// execution of contract update. Non-zero delay enables upgrade governance
+// and can be set by contract owner, after that delay can be changed by
+// alphabet nodes only.
+func SetUpgradeDelay(delay int) bool {
+	ctx := storage.GetContext()
+
+	if common.UpgradeDelay(ctx) == 0 {
+		if !common.HasUpdateAccess(ctx) {
+			panic("setUpgradeDelay: only owner can enable upgrade governance")
+		}
+	} else {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+		if !alphabetDecision(ctx, notaryDisabled, "setUpgradeDelay", []interface{}{delay}) {
+			return true
+		}
+	}
+
+	common.SetUpgradeDelay(ctx, delay)
+	runtime.Log("setUpgradeDelay: upgrade delay has been updated")
+
+	return true
+}
+
+// ProposeUpgrade proposes contract update with provided SHA256 hash of
+// concatenated SHA256 hashes of script and manifest. Available if upgrade
+// governance is enabled.
+func ProposeUpgrade(hash []byte) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		panic("proposeUpgrade: only owner can propose contract update")
+	}
+
+	common.ProposeUpgrade(ctx, hash)
+	runtime.Log("proposeUpgrade: contract update has been proposed")
+
+	return true
+}
+
+// ApproveUpgrade approves pending contract update with provided hash.
+// Approved update can be executed after upgrade delay.
+func ApproveUpgrade(hash []byte) bool {
+	ctx := storage.GetContext()
+	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+	if !alphabetDecision(ctx, notaryDisabled, "approveUpgrade", []interface{}{hash}) {
+		return true
+	}
+
+	common.ApproveUpgrade(ctx, hash)
+	runtime.Log("approveUpgrade: contract update has been approved")
+
+	return true
+}
+
+// CancelUpgrade removes pending contract update. Can be invoked by the
+// contract owner or alphabet nodes.
+func CancelUpgrade() bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+		proposal := common.GetUpgradeProposal(ctx)
+
+		if !alphabetDecision(ctx, notaryDisabled, "cancelUpgrade", []interface{}{proposal.Hash}) {
+			return true
+		}
+	}
+
+	common.CancelUpgrade(ctx)
+	runtime.Log("cancelUpgrade: contract update has been cancelled")
+
+	return true
+}
+
+// UpgradeProposal returns pending contract update.
+func UpgradeProposal() common.UpgradeProposal {
+	ctx := storage.GetReadOnlyContext()
+	return common.GetUpgradeProposal(ctx)
+}
+
+// UpgradeDelay returns amount of blocks between alphabet approval and
+// execution of contract update. Zero value means that upgrade governance
+// is disabled.
+func UpgradeDelay() int {
+	ctx := storage.GetReadOnlyContext()
+	return common.UpgradeDelay(ctx)
+}
+
+// Pause stops execution of the state-changing contract method. If method is
+// empty, then all user-facing methods are stopped, see userMethods. Can be
+// invoked by the contract owner or alphabet nodes.
+func Pause(method string) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+		if !alphabetDecision(ctx, notaryDisabled, "pause", []interface{}{method}) {
+			return true
+		}
+	}
+
+	common.Pause(ctx, method, userMethods, systemMethods)
+	runtime.Log("pause: contract method has been paused")
+
+	return true
+}
+
+// Unpause resumes execution of the contract method stopped by Pause. Can be
+// invoked by the contract owner or alphabet nodes.
+func Unpause(method string) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+		if !alphabetDecision(ctx, notaryDisabled, "unpause", []interface{}{method}) {
+			return true
+		}
+	}
+
+	common.Unpause(ctx, method)
+	runtime.Log("unpause: contract method has been unpaused")
+
+	return true
+}
+
+// IsPaused returns true if contract method is paused. Pause of the whole
+// contract affects only user-facing methods.
+func IsPaused(method string) bool {
+	ctx := storage.GetReadOnlyContext()
+	return common.IsPaused(ctx, method, userMethods)
+}
+
 // AlphabetList returns array of alphabet node keys.
 func AlphabetList() []common.IRNode {
 	ctx := storage.GetReadOnlyContext()
@@ -157,6 +376,7 @@ func InnerRingCandidates() []common.IRNode {
 // InnerRingCandidateRemove removes key from the list of inner ring candidates.
 func InnerRingCandidateRemove(key interop.PublicKey) bool {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "innerRingCandidateRemove", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 
 	var ( // for invocation collection without notary
@@ -194,12 +414,10 @@ func InnerRingCandidateRemove(key interop.PublicKey) bool {
 	}
 
 	if notaryDisabled && !keyOwner {
-		threshold := len(alphabet)*2/3 + 1
 		id := append(key, []byte("delete")...)
 		hashID := crypto.Sha256(id)
 
-		n := common.Vote(ctx, hashID, nodeKey)
-		if n < threshold {
+		if !common.Vote(ctx, "innerRingCandidateRemove", hashID, nodeKey, len(alphabet)) {
 			return true
 		}
 
@@ -214,6 +432,7 @@ func InnerRingCandidateRemove(key interop.PublicKey) bool {
 // InnerRingCandidateAdd adds key to the list of inner ring candidates.
 func InnerRingCandidateAdd(key interop.PublicKey) bool {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "innerRingCandidateAdd", userMethods)
 
 	if !runtime.CheckWitness(key) {
 		panic("irCandidateAdd: this method must be invoked by candidate")
@@ -244,6 +463,9 @@ func InnerRingCandidateAdd(key interop.PublicKey) bool {
 
 // OnNEP17Payment is a callback for NEP-17 compatible native GAS contract.
 func OnNEP17Payment(from interop.Hash160, amount int, data interface{}) {
+	ctx := storage.GetReadOnlyContext()
+	common.CheckPaused(ctx, "onNEP17Payment", userMethods)
+
 	rcv := data.(interop.Hash160)
 	if common.BytesEqual(rcv, []byte(ignoreDepositNotification)) {
 		return
@@ -270,6 +492,9 @@ func OnNEP17Payment(from interop.Hash160, amount int, data interface{}) {
 
 // Deposit gas assets to this script-hash address in NeoFS balance contract.
 func Deposit(from interop.Hash160, amount int, rcv interop.Hash160) bool {
+	ctx := storage.GetReadOnlyContext()
+	common.CheckPaused(ctx, "deposit", userMethods)
+
 	if !runtime.CheckWitness(from) {
 		panic("deposit: you should be the owner of the wallet")
 	}
@@ -308,6 +533,7 @@ func Withdraw(user interop.Hash160, amount int) bool {
 	}
 
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "withdraw", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 
 	// transfer fee to proxy contract to pay cheque invocation
@@ -345,6 +571,7 @@ func Withdraw(user interop.Hash160, amount int) bool {
 // locked in NeoFS balance contract.
 func Cheque(id []byte, user interop.Hash160, amount int, lockAcc []byte) bool {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "cheque", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 
 	var ( // for invocation collection without notary
@@ -368,10 +595,7 @@ func Cheque(id []byte, user interop.Hash160, amount int, lockAcc []byte) bool {
 	from := runtime.GetExecutingScriptHash()
 
 	if notaryDisabled {
-		threshold := len(alphabet)*2/3 + 1
-
-		n := common.Vote(ctx, id, nodeKey)
-		if n < threshold {
+		if !common.Vote(ctx, "cheque", id, nodeKey, len(alphabet)) {
 			return true
 		}
 
@@ -391,6 +615,9 @@ func Cheque(id []byte, user interop.Hash160, amount int, lockAcc []byte) bool {
 
 // Bind public key with user's account to use it in NeoFS requests.
 func Bind(user []byte, keys []interop.PublicKey) bool {
+	ctx := storage.GetReadOnlyContext()
+	common.CheckPaused(ctx, "bind", userMethods)
+
 	if !runtime.CheckWitness(user) {
 		panic("binding: you should be the owner of the wallet")
 	}
@@ -409,6 +636,9 @@ func Bind(user []byte, keys []interop.PublicKey) bool {
 
 // Unbind public key from user's account
 func Unbind(user []byte, keys []interop.PublicKey) bool {
+	ctx := storage.GetReadOnlyContext()
+	common.CheckPaused(ctx, "unbind", userMethods)
+
 	if !runtime.CheckWitness(user) {
 		panic("unbinding: you should be the owner of the wallet")
 	}
@@ -429,6 +659,7 @@ func Unbind(user []byte, keys []interop.PublicKey) bool {
 // public keys.
 func AlphabetUpdate(id []byte, args []interop.PublicKey) bool {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "alphabetUpdate", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 
 	if len(args) == 0 {
@@ -467,10 +698,7 @@ func AlphabetUpdate(id []byte, args []interop.PublicKey) bool {
 	}
 
 	if notaryDisabled {
-		threshold := len(alphabet)*2/3 + 1
-
-		n := common.Vote(ctx, id, nodeKey)
-		if n < threshold {
+		if !common.Vote(ctx, "alphabetUpdate", id, nodeKey, len(alphabet)) {
 			return true
 		}
 
@@ -494,6 +722,7 @@ func Config(key []byte) interface{} {
 // SetConfig key-value pair as a NeoFS runtime configuration value.
 func SetConfig(id, key, val []byte) bool {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "setConfig", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 
 	var ( // for invocation collection without notary
@@ -515,10 +744,7 @@ func SetConfig(id, key, val []byte) bool {
 	}
 
 	if notaryDisabled {
-		threshold := len(alphabet)*2/3 + 1
-
-		n := common.Vote(ctx, id, nodeKey)
-		if n < threshold {
+		if !common.Vote(ctx, "setConfig", id, nodeKey, len(alphabet)) {
 			return true
 		}
 
@@ -577,11 +803,128 @@ func InitConfig(args [][]byte) bool {
 	return true
 }
 
+// SetNotaryDisabled switches the way to collect signatures of alphabet
+// nodes. If notary gets disabled, then ballot storage is initialized,
+// otherwise all pending ballots are removed.
+func SetNotaryDisabled(notaryDisabled bool) bool {
+	ctx := storage.GetContext()
+	currentDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+	if notaryDisabled == currentDisabled {
+		panic("setNotaryDisabled: notary mode is already set")
+	}
+
+	if !alphabetDecision(ctx, currentDisabled, "setNotaryDisabled", []interface{}{}) {
+		return true
+	}
+
+	storage.Put(ctx, notaryDisabledKey, notaryDisabled)
+	if notaryDisabled {
+		common.InitVote(ctx)
+		runtime.Log("setNotaryDisabled: notary disabled")
+	} else {
+		common.PurgeVotes(ctx)
+		runtime.Log("setNotaryDisabled: notary enabled")
+	}
+
+	runtime.Notify("NotaryModeChanged", notaryDisabled)
+
+	return true
+}
+
+// RevokeVote removes vote of the invoking alphabet node from the pending
+// ballot with provided ID, if the decision has not reached quorum yet.
+func RevokeVote(id []byte) bool {
+	ctx := storage.GetContext()
+
+	alphabet := getNodes(ctx, alphabetKey)
+	nodeKey := common.InnerRingInvoker(alphabet)
+	if len(nodeKey) == 0 {
+		panic("revokeVote: this method must be invoked by alphabet")
+	}
+
+	if !common.RevokeVote(ctx, id, nodeKey) {
+		panic("revokeVote: there is no pending vote of the invoker")
+	}
+
+	runtime.Notify("VoteRevoked", id, nodeKey)
+	runtime.Log("revokeVote: vote has been revoked")
+
+	return true
+}
+
+// ListBallots returns list of pending ballots of the decisions made
+// without notary.
+func ListBallots() []common.BallotState {
+	ctx := storage.GetReadOnlyContext()
+	return common.Ballots(ctx)
+}
+
+// PurgeBallots removes at most limit expired ballots of the decisions made
+// without notary and returns amount of removed ballots. Only expired ballots
+// are removed, so method can be invoked by anyone.
+func PurgeBallots(limit int) int {
+	ctx := storage.GetContext()
+
+	if limit <= 0 {
+		panic("purgeBallots: invalid limit")
+	}
+
+	return common.PurgeExpiredVotes(ctx, limit)
+}
+
+// GetBallot returns pending ballot of the decision with provided ID.
+func GetBallot(id []byte) common.BallotState {
+	ctx := storage.GetReadOnlyContext()
+	return common.GetBallot(ctx, id)
+}
+
+// SetVotePolicy sets ballot expiry in blocks and quorum fraction of the
+// decisions made by contract method without notary. If method is empty,
+// then policy is set for all contract methods without own policy.
+func SetVotePolicy(method string, expiry, numerator, denominator int) bool {
+	ctx := storage.GetContext()
+	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+	policy := common.VotePolicy{
+		Expiry:      expiry,
+		Numerator:   numerator,
+		Denominator: denominator,
+	}
+
+	if !common.ValidVotePolicy(policy) {
+		panic("setVotePolicy: invalid vote policy")
+	}
+
+	if !alphabetDecision(ctx, notaryDisabled, "setVotePolicy", []interface{}{method, expiry, numerator, denominator}) {
+		return true
+	}
+
+	common.SetVotePolicy(ctx, method, policy)
+	runtime.Log("setVotePolicy: vote policy has been updated")
+
+	return true
+}
+
+// VotePolicy returns vote policy of the decisions made by contract method
+// without notary.
+func VotePolicy(method string) common.VotePolicy {
+	ctx := storage.GetReadOnlyContext()
+	return common.GetVotePolicy(ctx, method)
+}
+
 // Version of contract.
 func Version() int {
 	return version
 }
 
+// migrateStorage converts contract storage of the previous version to the
+// actual layout.
+func migrateStorage(ctx storage.Context, prev int) {
+	if prev < 4 {
+		// ballots are stored under their own keys since version 4
+		common.InitVote(ctx)
+	}
+}
+
 // getNodes returns deserialized slice of nodes from storage.
 func getNodes(ctx storage.Context, key string) []common.IRNode {
 	data := storage.Get(ctx, key)
@@ -592,6 +935,21 @@ func getNodes(ctx storage.Context, key string) []common.IRNode {
 	return []common.IRNode{}
 }
 
+// alphabetDecision checks that contract 'method' is invoked by main chain
+// alphabet nodes and returns true if the decision with specific 'args' must be
+// executed. See common.AlphabetDecision.
+func alphabetDecision(ctx storage.Context, notaryDisabled bool, method string, args []interface{}) bool {
+	if !notaryDisabled {
+		if !runtime.CheckWitness(AlphabetAddress()) {
+			panic(method + ": this method must be invoked by alphabet")
+		}
+
+		return true
+	}
+
+	return common.NodesDecision(ctx, getNodes(ctx, alphabetKey), method, args)
+}
+
 // getConfig returns installed neofs configuration value or nil if it is not set.
 func getConfig(ctx storage.Context, key interface{}) interface{} {
 	postfix := key.([]byte)
@@ -31,7 +31,7 @@ type (
 )
 
 const (
-	version = 1
+	version = 2
 
 	netmapKey         = "netmap"
 	configuredKey     = "initconfig"
@@ -58,6 +58,24 @@ var (
 	configPrefix = []byte("config")
 )
 
+// Pausable methods of the contract, see Pause.
+var (
+	// userMethods are stopped by the pause of the whole contract.
+	userMethods = []string{"addPeer", "updateState"}
+	// systemMethods are invoked by inner ring nodes, so they are stopped
+	// only by their own pause.
+	systemMethods = []string{"updateInnerRing", "newEpoch", "setConfig"}
+)
+
+func _deploy(data interface{}, isUpdate bool) {
+	ctx := storage.GetContext()
+
+	prev := common.CommitVersion(ctx, version)
+	if isUpdate {
+		migrateStorage(ctx, prev)
+	}
+}
+
 // Init function sets up initial list of inner ring public keys and should
 // be invoked once at neofs infrastructure setup.
 func Init(notaryDisabled bool, owner, addrBalance, addrContainer interop.Hash160, keys []interop.PublicKey) {
@@ -67,6 +85,8 @@ func Init(notaryDisabled bool, owner, addrBalance, addrContainer interop.Hash160
 		panic("only owner can reinitialize contract")
 	}
 
+	common.CheckNotGoverned(ctx, "init")
+
 	if len(addrBalance) != 20 || len(addrContainer) != 20 {
 		panic("init: incorrect length of contract script hash")
 	}
@@ -103,19 +123,214 @@ func Init(notaryDisabled bool, owner, addrBalance, addrContainer interop.Hash160
 }
 
 func Migrate(script []byte, manifest []byte) bool {
-	ctx := storage.GetReadOnlyContext()
+	ctx := storage.GetContext()
 
 	if !common.HasUpdateAccess(ctx) {
 		runtime.Log("only owner can update contract")
 		return false
 	}
 
+	common.CheckUpgrade(ctx, script, manifest)
 	management.Update(script, manifest)
 	runtime.Log("netmap contract updated")
 
 	return true
 }
 
+// ProposeOwner proposes new contract owner. Ownership is transferred after
+// proposed owner accepts it.
+func ProposeOwner(owner interop.Hash160) bool {
+	ctx := storage.GetContext()
+	common.ProposeOwner(ctx, owner)
+
+	return true
+}
+
+// AcceptOwnership transfers contract ownership to the proposed owner.
+func AcceptOwnership() bool {
+	ctx := storage.GetContext()
+	common.AcceptOwnership(ctx)
+
+	runtime.Log("netmap contract owner changed")
+
+	return true
+}
+
+// CancelOwnerProposal removes proposed contract owner.
+func CancelOwnerProposal() bool {
+	ctx := storage.GetContext()
+	common.CancelOwnerProposal(ctx)
+
+	return true
+}
+
+// PendingOwner returns proposed contract owner if there is one.
+func PendingOwner() interop.Hash160 {
+	ctx := storage.GetReadOnlyContext()
+	return common.PendingOwner(ctx)
+}
+
+// Migrations returns history of contract deploy and updates.
+func Migrations() []common.Migration {
+	ctx := storage.GetReadOnlyContext()
+	return common.Migrations(ctx)
+}
+
+// SetRegistry sets registry contract used to resolve addresses of other
+// NeoFS contracts. Addresses provided in Init are used if registry contract
+// can't resolve them.
+func SetRegistry(addr interop.Hash160) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		panic("setRegistry: only owner can set registry contract")
+	}
+
+	common.CheckNotGoverned(ctx, "setRegistry")
+
+	common.SetRegistry(ctx, addr)
+	runtime.Log("setRegistry: registry contract has been set")
+
+	return true
+}
+
+// SetUpgradeDelay sets amount of blocks between alphabet approval and
+// execution of contract update. Non-zero delay enables upgrade governance
+// and can be set by contract owner, after that delay can be changed by
+// alphabet nodes only.
+func SetUpgradeDelay(delay int) bool {
+	ctx := storage.GetContext()
+
+	if common.UpgradeDelay(ctx) == 0 {
+		if !common.HasUpdateAccess(ctx) {
+			panic("setUpgradeDelay: only owner can enable upgrade governance")
+		}
+	} else {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "setUpgradeDelay", []interface{}{delay}) {
+			return true
+		}
+	}
+
+	common.SetUpgradeDelay(ctx, delay)
+	runtime.Log("setUpgradeDelay: upgrade delay has been updated")
+
+	return true
+}
+
+// ProposeUpgrade proposes contract update with provided SHA256 hash of
+// concatenated SHA256 hashes of script and manifest. Available if upgrade
+// governance is enabled.
+func ProposeUpgrade(hash []byte) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		panic("proposeUpgrade: only owner can propose contract update")
+	}
+
+	common.ProposeUpgrade(ctx, hash)
+	runtime.Log("proposeUpgrade: contract update has been proposed")
+
+	return true
+}
+
+// ApproveUpgrade approves pending contract update with provided hash.
+// Approved update can be executed after upgrade delay.
+func ApproveUpgrade(hash []byte) bool {
+	ctx := storage.GetContext()
+	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+	if !common.AlphabetDecision(ctx, notaryDisabled, "approveUpgrade", []interface{}{hash}) {
+		return true
+	}
+
+	common.ApproveUpgrade(ctx, hash)
+	runtime.Log("approveUpgrade: contract update has been approved")
+
+	return true
+}
+
+// CancelUpgrade removes pending contract update. Can be invoked by the
+// contract owner or alphabet nodes.
+func CancelUpgrade() bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+		proposal := common.GetUpgradeProposal(ctx)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "cancelUpgrade", []interface{}{proposal.Hash}) {
+			return true
+		}
+	}
+
+	common.CancelUpgrade(ctx)
+	runtime.Log("cancelUpgrade: contract update has been cancelled")
+
+	return true
+}
+
+// UpgradeProposal returns pending contract update.
+func UpgradeProposal() common.UpgradeProposal {
+	ctx := storage.GetReadOnlyContext()
+	return common.GetUpgradeProposal(ctx)
+}
+
+// UpgradeDelay returns amount of blocks between alphabet approval and
+// execution of contract update. Zero value means that upgrade governance
+// is disabled.
+func UpgradeDelay() int {
+	ctx := storage.GetReadOnlyContext()
+	return common.UpgradeDelay(ctx)
+}
+
+// Pause stops execution of the state-changing contract method. If method is
+// empty, then all user-facing methods are stopped, see userMethods. Can be
+// invoked by the contract owner or alphabet nodes.
+func Pause(method string) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "pause", []interface{}{method}) {
+			return true
+		}
+	}
+
+	common.Pause(ctx, method, userMethods, systemMethods)
+	runtime.Log("pause: contract method has been paused")
+
+	return true
+}
+
+// Unpause resumes execution of the contract method stopped by Pause. Can be
+// invoked by the contract owner or alphabet nodes.
+func Unpause(method string) bool {
+	ctx := storage.GetContext()
+
+	if !common.HasUpdateAccess(ctx) {
+		notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+
+		if !common.AlphabetDecision(ctx, notaryDisabled, "unpause", []interface{}{method}) {
+			return true
+		}
+	}
+
+	common.Unpause(ctx, method)
+	runtime.Log("unpause: contract method has been unpaused")
+
+	return true
+}
+
+// IsPaused returns true if contract method is paused. Pause of the whole
+// contract affects only user-facing methods.
+func IsPaused(method string) bool {
+	ctx := storage.GetReadOnlyContext()
+	return common.IsPaused(ctx, method, userMethods)
+}
+
 func InnerRingList() []common.IRNode {
 	ctx := storage.GetReadOnlyContext()
 	return getIRNodes(ctx)
@@ -123,6 +338,7 @@ func InnerRingList() []common.IRNode {
 
 func UpdateInnerRing(keys []interop.PublicKey) bool {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "updateInnerRing", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 
 	var ( // for invocation collection without notary
@@ -151,11 +367,9 @@ func UpdateInnerRing(keys []interop.PublicKey) bool {
 	}
 
 	if notaryDisabled {
-		threshold := len(alphabet)*2/3 + 1
 		id := keysID(keys, []byte("updateIR"))
 
-		n := common.Vote(ctx, id, nodeKey)
-		if n < threshold {
+		if !common.Vote(ctx, "updateInnerRing", id, nodeKey, len(alphabet)) {
 			return true
 		}
 
@@ -170,6 +384,7 @@ func UpdateInnerRing(keys []interop.PublicKey) bool {
 
 func AddPeer(nodeInfo []byte) bool {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "addPeer", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 
 	var ( // for invocation collection without notary
@@ -188,7 +403,7 @@ func AddPeer(nodeInfo []byte) bool {
 	}
 
 	if !alphabetCall {
-		publicKey := nodeInfo[2:35] // offset:2, len:33
+		publicKey := common.NodeInfoKey(nodeInfo)
 		if !runtime.CheckWitness(publicKey) {
 			panic("addPeer: witness check failed")
 		}
@@ -203,12 +418,10 @@ func AddPeer(nodeInfo []byte) bool {
 	nm := addToNetmap(ctx, candidate)
 
 	if notaryDisabled {
-		threshold := len(alphabet)*2/3 + 1
 		rawCandidate := std.Serialize(candidate)
 		id := crypto.Sha256(rawCandidate)
 
-		n := common.Vote(ctx, id, nodeKey)
-		if n < threshold {
+		if !common.Vote(ctx, "addPeer", id, nodeKey, len(alphabet)) {
 			return true
 		}
 
@@ -231,6 +444,7 @@ func UpdateState(state int, publicKey interop.PublicKey) bool {
 	}
 
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "updateState", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 
 	var ( // for invocation collection without notary
@@ -259,11 +473,9 @@ func UpdateState(state int, publicKey interop.PublicKey) bool {
 	}
 
 	if notaryDisabled {
-		threshold := len(alphabet)*2/3 + 1
 		id := common.InvokeID([]interface{}{state, publicKey}, []byte("update"))
 
-		n := common.Vote(ctx, id, nodeKey)
-		if n < threshold {
+		if !common.Vote(ctx, "updateState", id, nodeKey, len(alphabet)) {
 			return true
 		}
 
@@ -284,6 +496,7 @@ func UpdateState(state int, publicKey interop.PublicKey) bool {
 
 func NewEpoch(epochNum int) bool {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "newEpoch", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 
 	var ( // for invocation collection without notary
@@ -305,11 +518,9 @@ func NewEpoch(epochNum int) bool {
 	}
 
 	if notaryDisabled {
-		threshold := len(alphabet)*2/3 + 1
 		id := common.InvokeID([]interface{}{epochNum}, []byte("epoch"))
 
-		n := common.Vote(ctx, id, nodeKey)
-		if n < threshold {
+		if !common.Vote(ctx, "newEpoch", id, nodeKey, len(alphabet)) {
 			return true
 		}
 
@@ -383,6 +594,7 @@ func Config(key []byte) interface{} {
 
 func SetConfig(id, key, val []byte) bool {
 	ctx := storage.GetContext()
+	common.CheckPaused(ctx, "setConfig", userMethods)
 	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
 
 	var ( // for invocation collection without notary
@@ -404,10 +616,7 @@ func SetConfig(id, key, val []byte) bool {
 	}
 
 	if notaryDisabled {
-		threshold := len(alphabet)*2/3 + 1
-
-		n := common.Vote(ctx, id, nodeKey)
-		if n < threshold {
+		if !common.Vote(ctx, "setConfig", id, nodeKey, len(alphabet)) {
 			return true
 		}
 
@@ -464,14 +673,131 @@ func ListConfig() []record {
 	return config
 }
 
+// SetNotaryDisabled switches the way to collect signatures of alphabet
+// nodes. If notary gets disabled, then ballot storage is initialized,
+// otherwise all pending ballots are removed.
+func SetNotaryDisabled(notaryDisabled bool) bool {
+	ctx := storage.GetContext()
+	currentDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+	if notaryDisabled == currentDisabled {
+		panic("setNotaryDisabled: notary mode is already set")
+	}
+
+	if !common.AlphabetDecision(ctx, currentDisabled, "setNotaryDisabled", []interface{}{}) {
+		return true
+	}
+
+	storage.Put(ctx, notaryDisabledKey, notaryDisabled)
+	if notaryDisabled {
+		common.InitVote(ctx)
+		runtime.Log("setNotaryDisabled: notary disabled")
+	} else {
+		common.PurgeVotes(ctx)
+		runtime.Log("setNotaryDisabled: notary enabled")
+	}
+
+	runtime.Notify("NotaryModeChanged", notaryDisabled)
+
+	return true
+}
+
+// RevokeVote removes vote of the invoking alphabet node from the pending
+// ballot with provided ID, if the decision has not reached quorum yet.
+func RevokeVote(id []byte) bool {
+	ctx := storage.GetContext()
+
+	alphabet := common.AlphabetNodes()
+	nodeKey := common.InnerRingInvoker(alphabet)
+	if len(nodeKey) == 0 {
+		panic("revokeVote: this method must be invoked by alphabet nodes")
+	}
+
+	if !common.RevokeVote(ctx, id, nodeKey) {
+		panic("revokeVote: there is no pending vote of the invoker")
+	}
+
+	runtime.Notify("VoteRevoked", id, nodeKey)
+	runtime.Log("revokeVote: vote has been revoked")
+
+	return true
+}
+
+// ListBallots returns list of pending ballots of the decisions made
+// without notary.
+func ListBallots() []common.BallotState {
+	ctx := storage.GetReadOnlyContext()
+	return common.Ballots(ctx)
+}
+
+// PurgeBallots removes at most limit expired ballots of the decisions made
+// without notary and returns amount of removed ballots. Only expired ballots
+// are removed, so method can be invoked by anyone.
+func PurgeBallots(limit int) int {
+	ctx := storage.GetContext()
+
+	if limit <= 0 {
+		panic("purgeBallots: invalid limit")
+	}
+
+	return common.PurgeExpiredVotes(ctx, limit)
+}
+
+// GetBallot returns pending ballot of the decision with provided ID.
+func GetBallot(id []byte) common.BallotState {
+	ctx := storage.GetReadOnlyContext()
+	return common.GetBallot(ctx, id)
+}
+
+// SetVotePolicy sets ballot expiry in blocks and quorum fraction of the
+// decisions made by contract method without notary. If method is empty,
+// then policy is set for all contract methods without own policy.
+func SetVotePolicy(method string, expiry, numerator, denominator int) bool {
+	ctx := storage.GetContext()
+	notaryDisabled := storage.Get(ctx, notaryDisabledKey).(bool)
+	policy := common.VotePolicy{
+		Expiry:      expiry,
+		Numerator:   numerator,
+		Denominator: denominator,
+	}
+
+	if !common.ValidVotePolicy(policy) {
+		panic("setVotePolicy: invalid vote policy")
+	}
+
+	if !common.AlphabetDecision(ctx, notaryDisabled, "setVotePolicy", []interface{}{method, expiry, numerator, denominator}) {
+		return true
+	}
+
+	common.SetVotePolicy(ctx, method, policy)
+	runtime.Log("setVotePolicy: vote policy has been updated")
+
+	return true
+}
+
+// VotePolicy returns vote policy of the decisions made by contract method
+// without notary.
+func VotePolicy(method string) common.VotePolicy {
+	ctx := storage.GetReadOnlyContext()
+	return common.GetVotePolicy(ctx, method)
+}
+
 func Version() int {
 	return version
 }
 
+// migrateStorage converts contract storage of the previous version to the
+// actual layout.
+func migrateStorage(ctx storage.Context, prev int) {
+	if prev < 2 {
+		// ballots are stored under their own keys since version 2
+		common.InitVote(ctx)
+	}
+}
+
 func addToNetmap(ctx storage.Context, n storageNode) []netmapNode {
 	var (
 		newNode    = n.info
-		newNodeKey = newNode[2:35]
+		newNodeKey = common.NodeInfoKey(newNode)
 
 		netmap = getNetmapNodes(ctx)
 		node   = netmapNode{
@@ -482,7 +808,7 @@ func addToNetmap(ctx storage.Context, n storageNode) []netmapNode {
 
 	for i := range netmap {
 		netmapNode := netmap[i].node.info
-		netmapNodeKey := netmapNode[2:35]
+		netmapNodeKey := common.NodeInfoKey(netmapNode)
 
 		if common.BytesEqual(newNodeKey, netmapNodeKey) {
 			return nil
@@ -503,7 +829,7 @@ func removeFromNetmap(ctx storage.Context, key interop.PublicKey) []netmapNode {
 	for i := 0; i < len(netmap); i++ {
 		item := netmap[i]
 		node := item.node.info
-		publicKey := node[2:35] // offset:2, len:33
+		publicKey := common.NodeInfoKey(node)
 
 		if !common.BytesEqual(publicKey, key) {
 			newNetmap = append(newNetmap, item)
@@ -562,10 +888,10 @@ func setConfig(ctx storage.Context, key, val interface{}) {
 }
 
 func cleanup(ctx storage.Context, epoch int) {
-	balanceContractAddr := storage.Get(ctx, balanceContractKey).(interop.Hash160)
+	balanceContractAddr := common.ContractAddress(ctx, common.BalanceContractName, balanceContractKey)
 	contract.Call(balanceContractAddr, cleanupEpochMethod, contract.All, epoch)
 
-	containerContractAddr := storage.Get(ctx, containerContractKey).(interop.Hash160)
+	containerContractAddr := common.ContractAddress(ctx, common.ContainerContractName, containerContractKey)
 	contract.Call(containerContractAddr, cleanupEpochMethod, contract.All, epoch)
 }
 